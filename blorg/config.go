@@ -29,6 +29,7 @@ type Config struct {
 	BaseUrl    string
 	Template   *template.Template
 	OrgConfig  *org.Configuration
+	Workspace  *org.Workspace // Workspace resolves links between the files of ContentDir (set by RenderContent).
 }
 
 var DefaultConfigFile = "blorg.org"
@@ -148,7 +149,12 @@ func (c *Config) Render() error {
 
 func (c *Config) RenderContent() ([]*Page, error) {
 	pages := []*Page{}
-	err := filepath.Walk(c.ContentDir, func(path string, info os.FileInfo, err error) error {
+	workspace, err := org.NewWorkspace(c.ContentDir, c.OrgConfig)
+	if err != nil {
+		return nil, err
+	}
+	c.Workspace = workspace
+	err = filepath.Walk(c.ContentDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"html/template"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/niklasfasching/go-org/org"
@@ -17,18 +18,34 @@ type Page struct {
 	Date           time.Time
 	Content        template.HTML
 	BufferSettings map[string]string
+	Backlinks      []Backlink // Backlinks are the links from other pages to the page (see org.Workspace.Backlinks).
+}
+
+// Backlink is a link from another page - it can be rendered as "Linked from" section by templates.
+type Backlink struct {
+	Title     string        // Title is the title of the linking page.
+	PermaLink string        // PermaLink is the url of the linking page (including the id of the linking headline).
+	Context   template.HTML // Context is the paragraph containing the link.
 }
 
 func NewPage(c *Config, path string, info os.FileInfo) (*Page, error) {
-	f, err := os.Open(path)
+	d, err := parsePage(c, path)
 	if err != nil {
 		return nil, err
 	}
-	d := c.OrgConfig.Parse(f, path)
 	content, err := d.Write(getWriter())
 	if err != nil {
 		return nil, err
 	}
+	if fileTags := d.FileTags(); len(fileTags) != 0 {
+		tags := strings.Fields(d.BufferSettings["TAGS[]"])
+		for _, tag := range fileTags {
+			if !strings.Contains(" "+d.BufferSettings["TAGS[]"]+" ", " "+tag+" ") {
+				tags = append(tags, tag)
+			}
+		}
+		d.BufferSettings["TAGS[]"] = strings.Join(tags, " ")
+	}
 	date, err := time.Parse("2006-01-02", d.Get("DATE"))
 	if err != nil {
 		date, _ = time.Parse("2006-01-02", "1970-01-01")
@@ -40,9 +57,75 @@ func NewPage(c *Config, path string, info os.FileInfo) (*Page, error) {
 		Date:           date,
 		Content:        template.HTML(content),
 		BufferSettings: d.BufferSettings,
+		Backlinks:      getBacklinks(c, path),
 	}, nil
 }
 
+// parsePage returns the document of the file at path - as parsed by the Workspace if it contains the file.
+func parsePage(c *Config, path string) (*org.Document, error) {
+	orgConfig := c.OrgConfig
+	if c.Workspace != nil {
+		if d, ok := c.Workspace.Document(path); ok {
+			return d, nil
+		}
+		orgConfig = c.Workspace.ConfigurationFor(path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return orgConfig.Parse(f, path), nil
+}
+
+func getBacklinks(c *Config, path string) []Backlink {
+	if c.Workspace == nil {
+		return nil
+	}
+	backlinks := []Backlink{}
+	for _, l := range c.Workspace.Backlinks(path) {
+		source := c.Workspace.Documents[l.Source.Path]
+		if source.BufferSettings["DRAFT"] != "" {
+			continue
+		}
+		title := c.Workspace.Titles[l.Source.Path]
+		if title == "" {
+			title = l.Source.Path
+		}
+		permaLink := c.BaseUrl + strings.TrimSuffix(l.Source.Path, ".org") + ".html"
+		if l.Source.ID != "" {
+			permaLink += "#" + l.Source.ID
+		}
+		backlinks = append(backlinks, Backlink{title, permaLink, template.HTML(writeBacklinkContext(source, l.Context))})
+	}
+	return backlinks
+}
+
+// writeBacklinkContext returns the html of the context of a backlink, written for the source document.
+// The urls of links and footnotes are relative to the source page - as the context is shown on the target page,
+// links are replaced by their descriptions and footnotes are removed.
+func writeBacklinkContext(source *org.Document, context org.Node) string {
+	nodes := org.Transform([]org.Node{context}, func(n org.Node) []org.Node {
+		switch n := n.(type) {
+		case org.RegularLink:
+			if n.Description != nil {
+				return n.Description
+			} else if _, title, ok := source.ResolveInternalLink(n); ok {
+				return title
+			}
+			return []org.Node{org.Text{Content: n.URL}}
+		case org.FootnoteLink:
+			return nil
+		}
+		return []org.Node{n}
+	})
+	w := getWriter()
+	w.Before(source)
+	w.Reset()
+	org.WriteNodes(w, nodes...)
+	return w.String()
+}
+
 func (p *Page) Render(path string) error {
 	if p.BufferSettings["DRAFT"] != "" {
 		return nil
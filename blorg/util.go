@@ -49,7 +49,7 @@ func slugify(s string) string {
 	return strings.Trim(s, "-")
 }
 
-func getWriter() org.Writer {
+func getWriter() *org.HTMLWriter {
 	w := org.NewHTMLWriter()
 	w.HighlightCodeBlock = highlightCodeBlock
 	return w
@@ -0,0 +1,230 @@
+package lsp
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/niklasfasching/go-org/org"
+)
+
+// keywords are offered as completions of #+ lines.
+var keywords = []string{
+	"TITLE:", "SUBTITLE:", "AUTHOR:", "DATE:", "EMAIL:", "DESCRIPTION:", "KEYWORDS:", "LANGUAGE:",
+	"OPTIONS:", "FILETAGS:", "TAGS:", "TODO:", "SEQ_TODO:", "TYP_TODO:", "EXCLUDE_TAGS:", "SELECT_TAGS:",
+	"SETUPFILE:", "INCLUDE:", "LINK:", "MACRO:", "NAME:", "CAPTION:", "ATTR_HTML:", "ATTR_LATEX:", "RESULTS:",
+	"HTML_HEAD:", "LATEX_CLASS:", "LATEX_CLASS_OPTIONS:", "LATEX_HEADER:", "PROPERTY:",
+	"BEGIN_SRC", "BEGIN_EXAMPLE", "BEGIN_EXPORT", "BEGIN_QUOTE", "BEGIN_CENTER", "BEGIN_VERSE", "BEGIN_COMMENT",
+}
+
+var keywordCompletionRegexp = regexp.MustCompile(`^\s*#\+(\w*)$`)
+var todoCompletionRegexp = regexp.MustCompile(`^\*+\s+(\w*)$`)
+var tagCompletionRegexp = regexp.MustCompile(`^\*+\s.*\s:(?:[\w@#%]+:)*([\w@#%]*)$`)
+
+func (d *document) diagnostics() []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, diagnostic := range d.Lint(nil) {
+		diagnostics = append(diagnostics, Diagnostic{d.rangeOf(diagnostic.Span), int(diagnostic.Severity), diagnostic.Code, "go-org", diagnostic.Message})
+	}
+	return diagnostics
+}
+
+// symbols returns a symbol for the headline of each section. The range of a symbol spans the whole section.
+func (d *document) symbols(sections []*org.Section) []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	for _, section := range sections {
+		h := section.Headline
+		name := strings.TrimSpace(org.String(h.Title...))
+		if name == "" {
+			name = strings.Repeat("*", h.Lvl)
+		}
+		r := d.rangeOf(h.Span)
+		selection := Range{r.Start, Position{r.Start.Line, utf16Len(d.lines[r.Start.Line])}}
+		symbols = append(symbols, DocumentSymbol{name, h.Status, symbolKindString, r, selection, d.symbols(section.Children)})
+	}
+	return symbols
+}
+
+// definition returns the location of the target of the footnote or link at p: The footnote definition,
+// the headline with a matching CUSTOM_ID (#id links), ID property (id:id links, in any open document) or title
+// (*title links), the radio target of a radio link or the target or #+NAME of the element named like the link.
+func (s *Server) definition(d *document, p Position) []Location {
+	offset, target := d.offset(p), org.Node(nil)
+	org.Inspect(func(n org.Node) bool {
+		span := org.SpanOf(n)
+		if !span.Contains(offset) {
+			return !span.IsValid()
+		}
+		switch n.(type) {
+		case org.FootnoteLink, org.RegularLink:
+			target = n
+		}
+		return true
+	}, d.Nodes...)
+	var found func(org.Node) bool
+	documents := []*document{d}
+	switch n := target.(type) {
+	case org.FootnoteLink:
+		found = func(m org.Node) bool {
+			f, ok := m.(org.FootnoteDefinition)
+			return ok && f.Name == n.Name && !f.Inline
+		}
+	case org.RegularLink:
+		switch {
+		case n.Protocol == "id":
+			documents = append(documents, s.sortedDocuments()...)
+			found = func(m org.Node) bool {
+				id, ok := "", false
+				if h, isHeadline := m.(org.Headline); isHeadline {
+					id, ok = h.Properties.Get("ID")
+				}
+				return ok && id == strings.TrimPrefix(n.URL, "id:")
+			}
+		case n.Protocol == "" && strings.HasPrefix(n.URL, "#"):
+			found = func(m org.Node) bool {
+				id, ok := "", false
+				if h, isHeadline := m.(org.Headline); isHeadline {
+					id, ok = h.Properties.Get("CUSTOM_ID")
+				}
+				return ok && id == n.URL[1:]
+			}
+		case n.Protocol == "" && strings.HasPrefix(n.URL, "*"):
+			found = func(m org.Node) bool {
+				h, ok := m.(org.Headline)
+				return ok && strings.TrimSpace(org.String(h.Title...)) == strings.TrimSpace(n.URL[1:])
+			}
+		case n.Protocol == "radio":
+			found = func(m org.Node) bool {
+				target, ok := m.(org.RadioTarget)
+				return ok && "radio:"+target.Name == n.URL
+			}
+		case n.Protocol == "":
+			found = func(m org.Node) bool {
+				switch m := m.(type) {
+				case org.NodeWithName:
+					return m.Name == n.URL
+				case org.Target:
+					return m.Name == n.URL
+				case org.RadioTarget:
+					return m.Name == n.URL
+				}
+				return false
+			}
+		}
+	}
+	if found == nil {
+		return nil
+	}
+	for _, d := range documents {
+		location := (*Location)(nil)
+		org.Inspect(func(n org.Node) bool {
+			if location == nil && found(n) {
+				location = &Location{d.uri, d.rangeOf(org.SpanOf(n))}
+			}
+			return location == nil
+		}, d.Nodes...)
+		if location != nil {
+			return []Location{*location}
+		}
+	}
+	return nil
+}
+
+// completion returns the TODO keywords at the start of a headline, the tags used in open documents (and defined by
+// #+TAGS and #+FILETAGS) after the title of a headline and the keywords (and block names) after #+.
+func (s *Server) completion(d *document, p Position) []CompletionItem {
+	if p.Line >= len(d.lines) {
+		return nil
+	}
+	line := d.lines[p.Line]
+	prefix := line[:d.offset(p)-d.offset(Position{p.Line, 0})]
+	items := []CompletionItem{}
+	complete := func(m []string, labels []string, kind int, detail string) []CompletionItem {
+		r := Range{Position{p.Line, p.Character - utf16Len(m[1])}, p}
+		for _, label := range labels {
+			if strings.HasPrefix(label, m[1]) {
+				items = append(items, CompletionItem{label, kind, detail, &TextEdit{r, label}})
+			}
+		}
+		return items
+	}
+	if m := keywordCompletionRegexp.FindStringSubmatch(prefix); m != nil {
+		return complete(m, keywords, completionKindKeyword, "keyword")
+	} else if m := todoCompletionRegexp.FindStringSubmatch(prefix); m != nil {
+		return complete(m, d.TodoKeywords().Keywords(), completionKindKeyword, "TODO keyword")
+	} else if m := tagCompletionRegexp.FindStringSubmatch(prefix); m != nil {
+		return complete(m, s.tags(), completionKindProperty, "tag")
+	}
+	return items
+}
+
+// tags returns the sorted tags of all open documents.
+func (s *Server) tags() []string {
+	tags := map[string]bool{}
+	for _, d := range s.documents {
+		definitions := d.TagDefinitions()
+		for _, tag := range append(d.FileTags(), definitions.Tags...) {
+			tags[tag] = true
+		}
+		for _, group := range definitions.Groups {
+			for _, tag := range append([]string{group.Name}, group.Tags...) {
+				tags[tag] = tag != ""
+			}
+		}
+		org.Inspect(func(n org.Node) bool {
+			if h, ok := n.(org.Headline); ok {
+				for _, tag := range h.Tags {
+					tags[tag] = true
+				}
+			}
+			return true
+		}, d.Nodes...)
+	}
+	sorted := []string{}
+	for tag, ok := range tags {
+		if ok {
+			sorted = append(sorted, tag)
+		}
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// foldingRanges returns the ranges of all headlines, blocks and drawers that span multiple lines.
+func (d *document) foldingRanges() []FoldingRange {
+	ranges := []FoldingRange{}
+	add := func(span org.Span) {
+		if r := d.rangeOf(span); span.IsValid() && r.End.Line > r.Start.Line {
+			ranges = append(ranges, FoldingRange{r.Start.Line, r.End.Line, "region"})
+		}
+	}
+	org.Inspect(func(n org.Node) bool {
+		switch n.(type) {
+		case org.Headline, org.Block, org.Drawer, org.PropertyDrawer:
+			add(org.SpanOf(n))
+		}
+		return true
+	}, d.Nodes...)
+	return ranges
+}
+
+// format returns the edits that format the document (see Document.Format).
+func (d *document) format() ([]TextEdit, error) {
+	out, err := d.Format()
+	if err != nil {
+		return nil, err
+	} else if out == d.text {
+		return []TextEdit{}, nil
+	}
+	return []TextEdit{{Range{Position{}, d.end()}, out}}, nil
+}
+
+// sortedDocuments returns all open documents sorted by uri.
+func (s *Server) sortedDocuments() []*document {
+	documents := []*document{}
+	for _, d := range s.documents {
+		documents = append(documents, d)
+	}
+	sort.Slice(documents, func(i, j int) bool { return documents[i].uri < documents[j].uri })
+	return documents
+}
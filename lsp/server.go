@@ -0,0 +1,255 @@
+// Package lsp implements a Language Server Protocol server for Org mode files (see go-org lsp).
+// The server speaks JSON-RPC over stdio and supports document symbols, diagnostics, go to definition,
+// completion, folding ranges and formatting.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/niklasfasching/go-org/org"
+)
+
+type Server struct {
+	Configuration *org.Configuration // Configuration is used to parse the open documents.
+
+	documents map[string]*document
+	out       io.Writer
+}
+
+// document is an open document: the text sent by the client and the Document it was parsed into.
+type document struct {
+	*org.Document
+	uri   string
+	text  string
+	lines []string
+}
+
+func (e *responseError) Error() string { return e.Message }
+
+func NewServer() *Server {
+	return &Server{
+		Configuration: org.New().Silent(),
+		documents:     map[string]*document{},
+	}
+}
+
+// Serve reads requests from in and writes responses to out until the client sends exit or closes in.
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	r := textproto.NewReader(bufio.NewReader(in))
+	s.out = out
+	for {
+		header, err := r.ReadMIMEHeader()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		length, err := strconv.Atoi(header.Get("Content-Length"))
+		if err != nil {
+			return fmt.Errorf("bad Content-Length header: %s", err)
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(r.R, body); err != nil {
+			return err
+		}
+		m := message{}
+		if err := json.Unmarshal(body, &m); err != nil {
+			if err := s.respond(nil, nil, &responseError{codeParseError, err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if m.Method == "exit" {
+			return nil
+		}
+		result, err := s.handle(m)
+		if m.ID == nil {
+			continue
+		} else if err := s.respond(m.ID, result, err); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(m message) (interface{}, error) {
+	switch m.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":           1, // the client sends the full text of a document on each change
+				"documentSymbolProvider":     true,
+				"definitionProvider":         true,
+				"completionProvider":         map[string]interface{}{"triggerCharacters": []string{"+", ":", " "}},
+				"foldingRangeProvider":       true,
+				"documentFormattingProvider": true,
+			},
+			"serverInfo": map[string]string{"name": "go-org"},
+		}, nil
+	case "initialized", "shutdown", "$/cancelRequest", "$/setTrace", "textDocument/didSave":
+		return nil, nil
+	case "textDocument/didOpen":
+		params := didOpenParams{}
+		if err := unmarshalParams(m.Params, &params); err != nil {
+			return nil, err
+		}
+		return nil, s.open(params.TextDocument.URI, params.TextDocument.Text)
+	case "textDocument/didChange":
+		params := didChangeParams{}
+		if err := unmarshalParams(m.Params, &params); err != nil {
+			return nil, err
+		} else if len(params.ContentChanges) == 0 {
+			return nil, nil
+		}
+		return nil, s.open(params.TextDocument.URI, params.ContentChanges[len(params.ContentChanges)-1].Text)
+	case "textDocument/didClose":
+		params := textDocumentParams{}
+		if err := unmarshalParams(m.Params, &params); err != nil {
+			return nil, err
+		}
+		delete(s.documents, params.TextDocument.URI)
+		return nil, s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{params.TextDocument.URI, []Diagnostic{}})
+	case "textDocument/documentSymbol":
+		d, err := s.document(m.Params, nil)
+		if err != nil {
+			return nil, err
+		}
+		return d.symbols(d.Outline.Children), nil
+	case "textDocument/definition":
+		p := Position{}
+		d, err := s.document(m.Params, &p)
+		if err != nil {
+			return nil, err
+		}
+		return s.definition(d, p), nil
+	case "textDocument/completion":
+		p := Position{}
+		d, err := s.document(m.Params, &p)
+		if err != nil {
+			return nil, err
+		}
+		return s.completion(d, p), nil
+	case "textDocument/foldingRange":
+		d, err := s.document(m.Params, nil)
+		if err != nil {
+			return nil, err
+		}
+		return d.foldingRanges(), nil
+	case "textDocument/formatting":
+		d, err := s.document(m.Params, nil)
+		if err != nil {
+			return nil, err
+		}
+		return d.format()
+	}
+	return nil, &responseError{codeMethodNotFound, "method not found: " + m.Method}
+}
+
+// open parses the text of the document uri and publishes its diagnostics.
+func (s *Server) open(uri, text string) error {
+	path := uri
+	if u, err := url.Parse(uri); err == nil && u.Scheme == "file" {
+		path = u.Path
+	}
+	d := &document{s.Configuration.Parse(strings.NewReader(text), path), uri, text, strings.Split(text, "\n")}
+	s.documents[uri] = d
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{uri, d.diagnostics()})
+}
+
+// document returns the open document of the text document params. The position of the params is stored in p if p is not nil.
+func (s *Server) document(params json.RawMessage, p *Position) (*document, error) {
+	ps := textDocumentPositionParams{}
+	if err := unmarshalParams(params, &ps); err != nil {
+		return nil, err
+	}
+	d, ok := s.documents[ps.TextDocument.URI]
+	if !ok {
+		return nil, &responseError{codeInvalidParams, "unknown document: " + ps.TextDocument.URI}
+	} else if p != nil {
+		*p = ps.Position
+	}
+	return d, nil
+}
+
+func (s *Server) respond(id *json.RawMessage, result interface{}, err error) error {
+	r := response{JSONRPC: "2.0", ID: id}
+	if err != nil {
+		responseErr, ok := err.(*responseError)
+		if !ok {
+			responseErr = &responseError{codeRequestFailed, err.Error()}
+		}
+		r.Error = responseErr
+	} else if r.Result, err = json.Marshal(result); err != nil {
+		return err
+	}
+	return s.write(r)
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	return s.write(notification{"2.0", method, params})
+}
+
+func (s *Server) write(v interface{}) error {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(bs), bs)
+	return err
+}
+
+func unmarshalParams(params json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(params, v); err != nil {
+		return &responseError{codeInvalidParams, err.Error()}
+	}
+	return nil
+}
+
+// position returns the LSP position of p, i.e. the zero based line and UTF-16 offset in the line.
+func (d *document) position(p org.Position) Position {
+	if !p.IsValid() || p.Line > len(d.lines) {
+		return Position{}
+	}
+	line, column := d.lines[p.Line-1], p.Column-1
+	if column > len(line) {
+		column = len(line)
+	}
+	return Position{p.Line - 1, utf16Len(line[:column])}
+}
+
+func (d *document) rangeOf(span org.Span) Range {
+	return Range{d.position(span.Start), d.position(span.End)}
+}
+
+// offset returns the byte offset of the LSP position p in the text of the document.
+func (d *document) offset(p Position) int {
+	offset := 0
+	for i := 0; i < p.Line && i < len(d.lines); i++ {
+		offset += len(d.lines[i]) + 1
+	}
+	if p.Line >= len(d.lines) {
+		return offset
+	}
+	n := 0
+	for i, r := range d.lines[p.Line] {
+		if n >= p.Character {
+			return offset + i
+		}
+		n += len(utf16.Encode([]rune{r}))
+	}
+	return offset + len(d.lines[p.Line])
+}
+
+// end returns the LSP position of the end of the text of the document.
+func (d *document) end() Position {
+	return Position{len(d.lines) - 1, utf16Len(d.lines[len(d.lines)-1])}
+}
+
+func utf16Len(s string) int { return len(utf16.Encode([]rune(s))) }
@@ -0,0 +1,162 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var testDocument = strings.Join([]string{
+	"#+TODO: TODO NEXT | DONE",
+	"* TODO first :work:",
+	":PROPERTIES:",
+	":CUSTOM_ID: first",
+	":ID: 42",
+	":END:",
+	"see [[#first]], [[id:42]], [[table]] and [fn:1]",
+	"** 日本語 child",
+	"#+NAME: table",
+	"| a |",
+	"#+BEGIN_SRC go",
+	"x := 1",
+	"#+END_SRC",
+	"* second [fn:missing]",
+	"[fn:1] footnote",
+	"* NE",
+	"* headline :wo",
+	"#+TI",
+}, "\n")
+
+func TestServer(t *testing.T) {
+	requests := []interface{}{
+		request(1, "initialize", map[string]interface{}{}),
+		request(0, "initialized", map[string]interface{}{}),
+		request(0, "textDocument/didOpen", map[string]interface{}{"textDocument": map[string]string{"uri": "file:///test.org", "text": testDocument}}),
+		request(2, "textDocument/documentSymbol", textDocument()),
+		request(3, "textDocument/definition", position(6, 8)),
+		request(4, "textDocument/definition", position(6, 20)),
+		request(5, "textDocument/definition", position(6, 30)),
+		request(6, "textDocument/definition", position(6, 44)),
+		request(7, "textDocument/completion", position(15, 4)),
+		request(8, "textDocument/completion", position(16, 14)),
+		request(9, "textDocument/completion", position(17, 4)),
+		request(10, "textDocument/foldingRange", textDocument()),
+		request(11, "unknown/method", textDocument()),
+		request(12, "shutdown", nil),
+		request(0, "exit", nil),
+	}
+	in := &bytes.Buffer{}
+	for _, r := range requests {
+		bs, _ := json.Marshal(r)
+		fmt.Fprintf(in, "Content-Length: %d\r\n\r\n%s", len(bs), bs)
+	}
+	out := &bytes.Buffer{}
+	if err := NewServer().Serve(in, out); err != nil {
+		t.Fatal(err)
+	}
+	messages := readMessages(t, out)
+	if len(messages) != 13 {
+		t.Fatalf("got %d messages, expected 13", len(messages))
+	}
+
+	diagnostics := messages[1]["params"].(map[string]interface{})["diagnostics"].([]interface{})
+	if len(diagnostics) != 1 || diagnostics[0].(map[string]interface{})["code"] != "missing-footnote-definition" {
+		t.Errorf("bad diagnostics: %v", diagnostics)
+	}
+	expected := map[int]string{
+		2:  `[{"name":"first","detail":"TODO","kind":15,"range":{"start":{"line":1,"character":0},"end":{"line":12,"character":9}},"selectionRange":{"start":{"line":1,"character":0},"end":{"line":1,"character":19}},"children":[{"name":"日本語 child","kind":15,"range":{"start":{"line":7,"character":0},"end":{"line":12,"character":9}},"selectionRange":{"start":{"line":7,"character":0},"end":{"line":7,"character":12}}}]},{"name":"second [fn:missing]","kind":15,"range":{"start":{"line":13,"character":0},"end":{"line":14,"character":15}},"selectionRange":{"start":{"line":13,"character":0},"end":{"line":13,"character":21}}},{"name":"NE","kind":15,"range":{"start":{"line":15,"character":0},"end":{"line":15,"character":4}},"selectionRange":{"start":{"line":15,"character":0},"end":{"line":15,"character":4}}},{"name":"headline :wo","kind":15,"range":{"start":{"line":16,"character":0},"end":{"line":17,"character":4}},"selectionRange":{"start":{"line":16,"character":0},"end":{"line":16,"character":14}}}]`,
+		3:  `[{"uri":"file:///test.org","range":{"start":{"line":1,"character":0},"end":{"line":12,"character":9}}}]`,
+		4:  `[{"uri":"file:///test.org","range":{"start":{"line":1,"character":0},"end":{"line":12,"character":9}}}]`,
+		5:  `[{"uri":"file:///test.org","range":{"start":{"line":8,"character":0},"end":{"line":9,"character":5}}}]`,
+		6:  `[{"uri":"file:///test.org","range":{"start":{"line":14,"character":0},"end":{"line":14,"character":15}}}]`,
+		7:  `[{"label":"NEXT","kind":14,"detail":"TODO keyword","textEdit":{"range":{"start":{"line":15,"character":2},"end":{"line":15,"character":4}},"newText":"NEXT"}}]`,
+		8:  `[{"label":"work","kind":10,"detail":"tag","textEdit":{"range":{"start":{"line":16,"character":12},"end":{"line":16,"character":14}},"newText":"work"}}]`,
+		9:  `[{"label":"TITLE:","kind":14,"detail":"keyword","textEdit":{"range":{"start":{"line":17,"character":2},"end":{"line":17,"character":4}},"newText":"TITLE:"}}]`,
+		10: `[{"startLine":1,"endLine":12,"kind":"region"},{"startLine":2,"endLine":5,"kind":"region"},{"startLine":7,"endLine":12,"kind":"region"},{"startLine":10,"endLine":12,"kind":"region"},{"startLine":13,"endLine":14,"kind":"region"},{"startLine":16,"endLine":17,"kind":"region"}]`,
+		12: `null`,
+	}
+	for _, m := range messages[2:] {
+		id := int(m["id"].(float64))
+		if id == 11 {
+			if m["error"].(map[string]interface{})["code"].(float64) != codeMethodNotFound {
+				t.Errorf("bad error response: %v", m)
+			}
+			continue
+		}
+		actual, _ := json.Marshal(m["result"])
+		if !jsonEqual(t, string(actual), expected[id]) {
+			t.Errorf("response %d:\ngot      %s\nexpected %s", id, actual, expected[id])
+		}
+	}
+}
+
+func TestFormatting(t *testing.T) {
+	s, out := NewServer(), &bytes.Buffer{}
+	s.out = out
+	if err := s.open("file:///test.org", "#+title: x\n* a :b:\n   + item\n"); err != nil {
+		t.Fatal(err)
+	}
+	edits, err := s.documents["file:///test.org"].format()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []TextEdit{{Range{Position{0, 0}, Position{3, 0}}, "#+TITLE: x\n* a" + strings.Repeat(" ", 71) + ":b:\n- item\n"}}
+	if !reflect.DeepEqual(edits, expected) {
+		t.Errorf("got %#v, expected %#v", edits, expected)
+	}
+}
+
+func request(id int, method string, params interface{}) map[string]interface{} {
+	r := map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params}
+	if id != 0 {
+		r["id"] = id
+	}
+	return r
+}
+
+func textDocument() map[string]interface{} {
+	return map[string]interface{}{"textDocument": map[string]string{"uri": "file:///test.org"}}
+}
+
+func position(line, character int) map[string]interface{} {
+	p := textDocument()
+	p["position"] = map[string]int{"line": line, "character": character}
+	return p
+}
+
+func readMessages(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	r, messages := textproto.NewReader(bufio.NewReader(out)), []map[string]interface{}{}
+	for {
+		header, err := r.ReadMIMEHeader()
+		if err != nil {
+			return messages
+		}
+		length, _ := strconv.Atoi(header.Get("Content-Length"))
+		body := make([]byte, length)
+		if _, err := io.ReadFull(r.R, body); err != nil {
+			t.Fatal(err)
+		}
+		m := map[string]interface{}{}
+		if err := json.Unmarshal(body, &m); err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, m)
+	}
+}
+
+func jsonEqual(t *testing.T, a, b string) bool {
+	var x, y interface{}
+	if err := json.Unmarshal([]byte(a), &x); err != nil {
+		t.Fatal(err)
+	} else if err := json.Unmarshal([]byte(b), &y); err != nil {
+		t.Fatal(err)
+	}
+	return reflect.DeepEqual(x, y)
+}
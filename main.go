@@ -1,27 +1,51 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 	"runtime/debug"
 	"strings"
+	"time"
 
 	"github.com/alecthomas/chroma/v2"
 	"github.com/alecthomas/chroma/v2/formatters/html"
 	"github.com/alecthomas/chroma/v2/lexers"
 	"github.com/alecthomas/chroma/v2/styles"
 	"github.com/niklasfasching/go-org/blorg"
+	"github.com/niklasfasching/go-org/lsp"
 	"github.com/niklasfasching/go-org/org"
+	"github.com/pmezard/go-difflib/difflib"
 )
 
 var usage = `Usage: go-org COMMAND [ARGS]...
 Commands:
-- render [FILE] FORMAT
-  FORMAT: org, html, html-chroma
+- render [-width N] [-ascii] [FILE] FORMAT
+  FORMAT: org, html, html-chroma, md, latex, txt, ics, json
   Instead of specifying a file, org mode content can also be passed on stdin
+  -width, -ascii: line width (0 disables wrapping) and ASCII only output of the txt format
+- clocktable FILE
+  Prints the clocked time per headline, tag and day as Org mode tables
+- agenda [-view day|week|todo] [-date YYYY-MM-DD] [-tags a,b] [-priorities A,B] [-statuses TODO,NEXT] [-format text|json|org] FILE...
+  Prints the agenda (scheduled items, deadlines and active timestamps) or the global TODO list of the files
+- fmt [-w] [-d] FILE...
+  Formats the files as canonical Org mode and prints the result
+  -w: writes the result to the files instead
+  -d: prints a diff instead and exits with status 1 if any file is not formatted
+- lint FILE...
+  Prints problems found in the files as FILE:LINE:COLUMN: SEVERITY: MESSAGE [CODE]
+  id links are checked against the :ID: properties of all FILEs
+  Exits with status 1 if any errors or warnings were found
+- lsp
+  Runs a Language Server Protocol server for Org mode files on stdin and stdout
+- graph [-format dot|json] DIR
+  Prints the graph of the links between the Org mode files (and their headlines) in DIR
 - blorg
   - blorg init
   - blorg build
@@ -36,6 +60,20 @@ func main() {
 	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
 	case "render":
 		render(args)
+	case "clocktable":
+		clocktable(args)
+	case "agenda":
+		agenda(args)
+	case "fmt":
+		format(args)
+	case "lint":
+		lint(args)
+	case "lsp":
+		if err := lsp.NewServer().Serve(os.Stdin, os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+	case "graph":
+		graph(args)
 	case "blorg":
 		runBlorg(args)
 	case "version":
@@ -82,6 +120,11 @@ func runBlorg(args []string) {
 }
 
 func render(args []string) {
+	flags := flag.NewFlagSet("render", flag.ExitOnError)
+	width := flags.Int("width", 72, "line width of the txt format - 0 disables wrapping")
+	ascii := flags.Bool("ascii", false, "ASCII only output of the txt format")
+	flags.Parse(args)
+	args = flags.Args()
 	r, path, format := io.Reader(nil), "", ""
 	if fi, err := os.Stdin.Stat(); err != nil {
 		log.Fatal(err)
@@ -114,6 +157,319 @@ func render(args []string) {
 		writer := org.NewHTMLWriter()
 		writer.HighlightCodeBlock = highlightCodeBlock
 		write(writer)
+	case "md":
+		write(org.NewMarkdownWriter())
+	case "latex":
+		write(org.NewLaTeXWriter())
+	case "txt":
+		writer := org.NewTextWriter()
+		writer.Width, writer.ASCII = *width, *ascii
+		write(writer)
+	case "ics":
+		if d.Error != nil {
+			log.Fatal(d.Error)
+		}
+		fmt.Fprint(os.Stdout, d.ICalendar())
+	case "json":
+		if d.Error != nil {
+			log.Fatal(d.Error)
+		}
+		bs, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Fprintln(os.Stdout, string(bs))
+	default:
+		log.Fatal(usage)
+	}
+}
+
+func clocktable(args []string) {
+	if len(args) != 1 {
+		log.Fatal(usage)
+	}
+	f, err := os.Open(args[0])
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	d := org.New().Parse(f, args[0])
+	if d.Error != nil {
+		log.Fatal(d.Error)
+	}
+	fmt.Fprint(os.Stdout, d.ClockReport())
+}
+
+var agendaKindLabels = map[string]string{"scheduled": "Scheduled:", "deadline": "Deadline:"}
+
+type agendaJSONItem struct {
+	File     string   `json:"file"`
+	Headline string   `json:"headline"`
+	Status   string   `json:"status,omitempty"`
+	Priority string   `json:"priority,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Kind     string   `json:"kind"`
+	Date     string   `json:"date,omitempty"`
+	Time     string   `json:"time,omitempty"`
+}
+
+func agenda(args []string) {
+	flags := flag.NewFlagSet("agenda", flag.ExitOnError)
+	view := flags.String("view", "week", "day, week or todo")
+	date := flags.String("date", time.Now().Format("2006-01-02"), "first day of the agenda (the week view starts on its monday)")
+	tags := flags.String("tags", "", "comma separated tags that headlines must have")
+	priorities := flags.String("priorities", "", "comma separated priorities that headlines must have one of")
+	statuses := flags.String("statuses", "", "comma separated statuses that headlines must have one of")
+	format := flags.String("format", "text", "text, json or org")
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		log.Fatal(usage)
+	}
+	documents := []*org.Document{}
+	for _, path := range flags.Args() {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		d := org.New().Silent().Parse(f, path)
+		f.Close()
+		if d.Error != nil {
+			log.Fatalf("%s: %s", path, d.Error)
+		}
+		documents = append(documents, d)
+	}
+	filter := org.AgendaFilter{Tags: splitFlag(*tags), Priorities: splitFlag(*priorities), Statuses: splitFlag(*statuses)}
+	from, err := time.Parse("2006-01-02", *date)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var days []time.Time
+	switch *view {
+	case "day":
+		days = append(days, from)
+	case "week":
+		from = from.AddDate(0, 0, -(int(from.Weekday())+6)%7)
+		for i := 0; i < 7; i++ {
+			days = append(days, from.AddDate(0, 0, i))
+		}
+	case "todo":
+	default:
+		log.Fatal(usage)
+	}
+	var items []org.AgendaItem
+	if *view == "todo" {
+		items = org.TodoList(documents, filter)
+	} else {
+		items = org.Agenda(documents, from, from.AddDate(0, 0, len(days)), filter)
+	}
+	switch *format {
+	case "text":
+		writeAgendaText(os.Stdout, days, items)
+	case "json":
+		writeAgendaJSON(os.Stdout, items)
+	case "org":
+		writeAgendaOrg(os.Stdout, days, items)
+	default:
+		log.Fatal(usage)
+	}
+}
+
+func writeAgendaText(w io.Writer, days []time.Time, items []org.AgendaItem) {
+	line := func(item org.AgendaItem) string {
+		h := item.Headline
+		title := strings.TrimSpace(org.String(h.Title...))
+		if p := priority(item.Document, h); p != "" {
+			title = p + " " + title
+		}
+		if h.Status != "" {
+			title = h.Status + " " + title
+		}
+		if len(item.Tags) != 0 {
+			title += "  :" + strings.Join(item.Tags, ":") + ":"
+		}
+		file := filepath.Base(item.Document.Path) + ":"
+		if item.Timestamp == nil {
+			return fmt.Sprintf("%-12s %s", file, title)
+		}
+		when := ""
+		if !item.Timestamp.IsDate {
+			when = item.Time.Format("15:04")
+		}
+		return fmt.Sprintf("%-12s %5s %-10s %s", file, when, agendaKindLabels[item.Kind], title)
+	}
+	if days == nil {
+		for _, item := range items {
+			fmt.Fprintln(w, line(item))
+		}
+		return
+	}
+	for _, day := range days {
+		fmt.Fprintln(w, day.Format("Monday 2006-01-02"))
+		for _, item := range items {
+			if item.Time.Format("2006-01-02") == day.Format("2006-01-02") {
+				fmt.Fprintln(w, "  "+line(item))
+			}
+		}
+	}
+}
+
+func writeAgendaJSON(w io.Writer, items []org.AgendaItem) {
+	jsonItems := make([]agendaJSONItem, len(items))
+	for i, item := range items {
+		h := item.Headline
+		jsonItems[i] = agendaJSONItem{item.Document.Path, strings.TrimSpace(org.String(h.Title...)), h.Status, h.EffectivePriority(item.Document), item.Tags, item.Kind, "", ""}
+		if item.Timestamp != nil {
+			jsonItems[i].Date = item.Time.Format("2006-01-02")
+			if !item.Timestamp.IsDate {
+				jsonItems[i].Time = item.Time.Format("15:04")
+			}
+		}
+	}
+	bs, err := json.MarshalIndent(jsonItems, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Fprintln(w, string(bs))
+}
+
+func writeAgendaOrg(w io.Writer, days []time.Time, items []org.AgendaItem) {
+	headline := func(item org.AgendaItem, lvl int) org.Headline {
+		h := item.Headline
+		agendaHeadline := org.Headline{Lvl: lvl, Status: h.Status, Priority: h.Priority, Title: h.Title, Tags: item.Tags}
+		switch item.Kind {
+		case "scheduled":
+			agendaHeadline.Scheduled = item.Timestamp
+		case "deadline":
+			agendaHeadline.Deadline = item.Timestamp
+		case "timestamp":
+			agendaHeadline.Children = []org.Node{org.Paragraph{Children: []org.Node{*item.Timestamp}}}
+		}
+		return agendaHeadline
+	}
+	if days == nil {
+		for _, item := range items {
+			fmt.Fprint(w, org.String(headline(item, 1)))
+		}
+		return
+	}
+	for _, day := range days {
+		fmt.Fprintln(w, "* "+day.Format("Monday 2006-01-02"))
+		for _, item := range items {
+			if item.Time.Format("2006-01-02") == day.Format("2006-01-02") {
+				fmt.Fprint(w, org.String(headline(item, 2)))
+			}
+		}
+	}
+}
+
+func priority(d *org.Document, h org.Headline) string {
+	p := h.EffectivePriority(d)
+	if p == "" {
+		return ""
+	}
+	return "[#" + p + "]"
+}
+
+func splitFlag(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == ',' })
+}
+
+func format(args []string) {
+	flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+	write := flags.Bool("w", false, "write result to the files instead of printing it")
+	printDiff := flags.Bool("d", false, "print diffs instead of the result")
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		log.Fatal(usage)
+	}
+	unformatted := false
+	for _, path := range flags.Args() {
+		bs, err := ioutil.ReadFile(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		out, err := org.New().Silent().Parse(bytes.NewReader(bs), path).Format()
+		if err != nil {
+			log.Fatalf("%s: %s", path, err)
+		}
+		switch {
+		case *printDiff:
+			diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+				A:        difflib.SplitLines(string(bs)),
+				B:        difflib.SplitLines(out),
+				FromFile: path + ".orig",
+				ToFile:   path,
+				Context:  3,
+			})
+			fmt.Fprint(os.Stdout, diff)
+			unformatted = unformatted || diff != ""
+		case *write:
+			if out != string(bs) {
+				if err := ioutil.WriteFile(path, []byte(out), 0644); err != nil {
+					log.Fatal(err)
+				}
+			}
+		default:
+			fmt.Fprint(os.Stdout, out)
+		}
+	}
+	if unformatted {
+		os.Exit(1)
+	}
+}
+
+func lint(args []string) {
+	if len(args) == 0 {
+		log.Fatal(usage)
+	}
+	documents, ids := make([]*org.Document, len(args)), map[string]bool{}
+	for i, path := range args {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		documents[i] = org.New().Silent().Parse(f, path)
+		f.Close()
+		for _, id := range documents[i].IDs() {
+			ids[id] = true
+		}
+	}
+	failed := false
+	for i, path := range args {
+		for _, diagnostic := range documents[i].Lint(ids) {
+			if diagnostic.Span.IsValid() {
+				fmt.Fprintf(os.Stdout, "%s:%s\n", path, diagnostic)
+			} else {
+				fmt.Fprintf(os.Stdout, "%s: %s\n", path, diagnostic)
+			}
+			failed = failed || diagnostic.Severity != org.SeverityInfo
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func graph(args []string) {
+	flags := flag.NewFlagSet("graph", flag.ExitOnError)
+	format := flags.String("format", "dot", "dot or json")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		log.Fatal(usage)
+	}
+	w, err := org.NewWorkspace(flags.Arg(0), org.New().Silent())
+	if err != nil {
+		log.Fatal(err)
+	}
+	switch g := w.Graph(); *format {
+	case "dot":
+		fmt.Fprint(os.Stdout, g.DOT())
+	case "json":
+		bs, err := json.MarshalIndent(g, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Fprintln(os.Stdout, string(bs))
 	default:
 		log.Fatal(usage)
 	}
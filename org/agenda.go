@@ -0,0 +1,158 @@
+package org
+
+import (
+	"sort"
+	"time"
+)
+
+// AgendaItem is a headline that shows up in an agenda view or TODO list.
+type AgendaItem struct {
+	Document  *Document
+	Headline  Headline
+	Tags      []string   // Tags contains the effective tags of the headline (see Headline.EffectiveTags).
+	Kind      string     // Kind is one of scheduled, deadline, timestamp or todo.
+	Timestamp *Timestamp // Timestamp is the timestamp the item was collected for. It is nil for todo items.
+	Time      time.Time  // Time is the occurrence of Timestamp the item was collected for.
+}
+
+// AgendaFilter restricts the headlines of an agenda. Empty fields do not restrict anything.
+type AgendaFilter struct {
+	Tags       []string // Tags contains tags that a headline must all have (including inherited tags and #+TAGS group tags).
+	Priorities []string // Priorities contains the allowed headline priorities (see Headline.EffectivePriority).
+	Statuses   []string // Statuses contains the allowed headline statuses (e.g. TODO).
+}
+
+// Agenda returns the agenda items of documents with occurrences in [from, to), sorted by time.
+// Items are collected for SCHEDULED and DEADLINE timestamps of headlines that are not done and
+// for active timestamps in headline titles and sections. Repeaters and date ranges are expanded.
+func Agenda(documents []*Document, from, to time.Time, filter AgendaFilter) []AgendaItem {
+	items := []AgendaItem{}
+	for _, d := range documents {
+		d.walkAgendaHeadlines(d.Nodes, filter, func(h Headline, tags []string) {
+			add := func(kind string, t *Timestamp) {
+				for _, o := range agendaOccurrences(*t, from, to) {
+					items = append(items, AgendaItem{d, h, tags, kind, t, o})
+				}
+			}
+			if !d.TodoKeywords().IsDone(h.Status) {
+				if h.Scheduled != nil {
+					add("scheduled", h.Scheduled)
+				}
+				if h.Deadline != nil {
+					add("deadline", h.Deadline)
+				}
+			}
+			for _, t := range collectTimestamps(append(append([]Node{}, h.Title...), h.Children...)) {
+				if !t.IsInactive {
+					t := t
+					add("timestamp", &t)
+				}
+			}
+		})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if !items[i].Time.Equal(items[j].Time) {
+			return items[i].Time.Before(items[j].Time)
+		}
+		return comparePriorities(items[i], items[j]) < 0
+	})
+	return items
+}
+
+// TodoList returns the headlines of documents that have a status that is not done, sorted by priority.
+func TodoList(documents []*Document, filter AgendaFilter) []AgendaItem {
+	items := []AgendaItem{}
+	for _, d := range documents {
+		d.walkAgendaHeadlines(d.Nodes, filter, func(h Headline, tags []string) {
+			if h.Status != "" && !d.TodoKeywords().IsDone(h.Status) {
+				items = append(items, AgendaItem{d, h, tags, "todo", nil, time.Time{}})
+			}
+		})
+	}
+	sort.SliceStable(items, func(i, j int) bool { return comparePriorities(items[i], items[j]) < 0 })
+	return items
+}
+
+// comparePriorities compares the priorities of the headlines of a and b relative to the priority range of their documents.
+func comparePriorities(a, b AgendaItem) int {
+	pa, pb := a.Document.Priorities(), b.Document.Priorities()
+	return pa.rank(a.Headline.Priority) - pb.rank(b.Headline.Priority)
+}
+
+func (d *Document) walkAgendaHeadlines(nodes []Node, filter AgendaFilter, f func(Headline, []string)) {
+	for _, n := range nodes {
+		h, ok := n.(Headline)
+		if !ok || h.IsComment {
+			continue
+		}
+		if tags := h.EffectiveTags(); filter.matches(d, h, d.TagDefinitions().Expand(tags)) {
+			f(h, tags)
+		}
+		d.walkAgendaHeadlines(h.Children, filter, f)
+	}
+}
+
+func (filter AgendaFilter) matches(d *Document, h Headline, tags []string) bool {
+	for _, tag := range filter.Tags {
+		if !containsString(tags, tag) {
+			return false
+		}
+	}
+	if len(filter.Priorities) != 0 && !containsString(filter.Priorities, h.EffectivePriority(d)) {
+		return false
+	}
+	if len(filter.Statuses) != 0 && !containsString(filter.Statuses, h.Status) {
+		return false
+	}
+	return true
+}
+
+// agendaOccurrences returns the occurrences of t in [from, to). Every day of a date range occurs.
+func agendaOccurrences(t Timestamp, from, to time.Time) []time.Time {
+	to = to.Add(-time.Nanosecond)
+	if t.End == nil || t.IsTimeRange {
+		return t.Occurrences(from, to)
+	}
+	occurrences := []time.Time{}
+	for day := t.Time; !day.After(t.End.Time); day = day.AddDate(0, 0, 1) {
+		if !day.Before(from) && !day.After(to) {
+			occurrences = append(occurrences, day)
+		}
+	}
+	return occurrences
+}
+
+// collectTimestamps returns the timestamps in nodes - excluding those of sub headlines and planning lines.
+func collectTimestamps(nodes []Node) []Timestamp {
+	timestamps := []Timestamp{}
+	Inspect(func(n Node) bool {
+		switch n := n.(type) {
+		case Headline:
+			return false
+		case Timestamp:
+			timestamps = append(timestamps, n)
+			return false
+		}
+		return true
+	}, nodes...)
+	return timestamps
+}
+
+func containsString(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func uniqueStrings(ss []string) []string {
+	seen, unique := map[string]bool{}, []string{}
+	for _, s := range ss {
+		if !seen[s] {
+			seen[s], unique = true, append(unique, s)
+		}
+	}
+	return unique
+}
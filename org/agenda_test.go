@@ -0,0 +1,50 @@
+package org
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAgenda(t *testing.T) {
+	d := New().Silent().Parse(strings.NewReader(`#+TODO: TODO NEXT | DONE
+* Work :work:
+** TODO [#A] Write report
+SCHEDULED: <2024-01-01 Mon 10:00 +1d>
+** NEXT Ship it
+DEADLINE: <2024-01-03 Wed>
+** DONE Finished
+SCHEDULED: <2024-01-02 Tue>
+* Meeting <2024-01-02 Tue 14:00>
+* Trip
+<2024-01-04 Thu>--<2024-01-05 Fri>
+`), "")
+	format := func(items []AgendaItem) string {
+		lines := []string{}
+		for _, item := range items {
+			line := item.Kind + " " + strings.TrimSpace(String(item.Headline.Title...))
+			if item.Timestamp != nil {
+				line = item.Time.Format("01-02 15:04 ") + line
+			}
+			lines = append(lines, line)
+		}
+		return strings.Join(lines, "\n")
+	}
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	actual := format(Agenda([]*Document{d}, from, from.AddDate(0, 0, 3), AgendaFilter{}))
+	expected := strings.Join([]string{
+		"01-02 10:00 scheduled Write report",
+		"01-02 14:00 timestamp Meeting <2024-01-02 Tue 14:00>",
+		"01-03 00:00 deadline Ship it",
+		"01-03 10:00 scheduled Write report",
+		"01-04 00:00 timestamp Trip",
+		"01-04 10:00 scheduled Write report",
+	}, "\n")
+	if actual != expected {
+		t.Errorf("unexpected agenda:\n%s", diff(actual, expected))
+	}
+	actual = format(TodoList([]*Document{d}, AgendaFilter{Tags: []string{"work"}, Statuses: []string{"NEXT"}}))
+	if expected := "todo Ship it"; actual != expected {
+		t.Errorf("unexpected todo list:\n%s", diff(actual, expected))
+	}
+}
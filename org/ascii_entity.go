@@ -0,0 +1,420 @@
+package org
+
+import (
+	"sort"
+	"strings"
+)
+
+// asciiEntityReplacer replaces org entities with their ASCII representation.
+var asciiEntityReplacer *strings.Replacer
+
+func init() {
+	for i := 1; i <= 20; i++ {
+		asciiEntities = append(asciiEntities, []string{`\_` + strings.Repeat(" ", i), strings.Repeat(" ", i)})
+	}
+	// replace longer entities first to prevent partial replacements (e.g. \angle as \ang + le)
+	sort.SliceStable(asciiEntities, func(i, j int) bool {
+		return len(asciiEntities[i][0]) > len(asciiEntities[j][0])
+	})
+	xs := []string{}
+	for _, kv := range asciiEntities {
+		// replace both \<entity> and \<entity>{}
+		xs = append(xs, kv[0]+"{}", kv[1], kv[0], kv[1])
+	}
+	asciiEntityReplacer = strings.NewReplacer(xs...)
+}
+
+// asciiEntities maps org entities to their ASCII representation - based on the ASCII column of org-entities.
+var asciiEntities = [][]string{
+	{`\Agrave`, `A`},
+	{`\agrave`, `a`},
+	{`\Aacute`, `A`},
+	{`\aacute`, `a`},
+	{`\Acirc`, `A`},
+	{`\acirc`, `a`},
+	{`\Amacr`, `A`},
+	{`\amacr`, `a`},
+	{`\Atilde`, `A`},
+	{`\atilde`, `a`},
+	{`\Auml`, `Ae`},
+	{`\auml`, `ae`},
+	{`\Aring`, `A`},
+	{`\AA`, `A`},
+	{`\aring`, `a`},
+	{`\AElig`, `AE`},
+	{`\aelig`, `ae`},
+	{`\Ccedil`, `C`},
+	{`\ccedil`, `c`},
+	{`\Egrave`, `E`},
+	{`\egrave`, `e`},
+	{`\Eacute`, `E`},
+	{`\eacute`, `e`},
+	{`\Ecirc`, `E`},
+	{`\ecirc`, `e`},
+	{`\Euml`, `E`},
+	{`\euml`, `e`},
+	{`\Igrave`, `I`},
+	{`\igrave`, `i`},
+	{`\Iacute`, `I`},
+	{`\iacute`, `i`},
+	{`\Idot`, `I`},
+	{`\inodot`, `i`},
+	{`\Icirc`, `I`},
+	{`\icirc`, `i`},
+	{`\Iuml`, `I`},
+	{`\iuml`, `i`},
+	{`\Ntilde`, `N`},
+	{`\ntilde`, `n`},
+	{`\Ograve`, `O`},
+	{`\ograve`, `o`},
+	{`\Oacute`, `O`},
+	{`\oacute`, `o`},
+	{`\Ocirc`, `O`},
+	{`\ocirc`, `o`},
+	{`\Otilde`, `O`},
+	{`\otilde`, `o`},
+	{`\Ouml`, `Oe`},
+	{`\ouml`, `oe`},
+	{`\Oslash`, `O`},
+	{`\oslash`, `o`},
+	{`\OElig`, `OE`},
+	{`\oelig`, `oe`},
+	{`\Scaron`, `S`},
+	{`\scaron`, `s`},
+	{`\szlig`, `ss`},
+	{`\Ugrave`, `U`},
+	{`\ugrave`, `u`},
+	{`\Uacute`, `U`},
+	{`\uacute`, `u`},
+	{`\Ucirc`, `U`},
+	{`\ucirc`, `u`},
+	{`\Uuml`, `Ue`},
+	{`\uuml`, `ue`},
+	{`\Yacute`, `Y`},
+	{`\yacute`, `y`},
+	{`\Yuml`, `Y`},
+	{`\yuml`, `y`},
+	{`\fnof`, `f`},
+	{`\real`, `R`},
+	{`\image`, `I`},
+	{`\weierp`, `P`},
+	{`\ell`, `ell`},
+	{`\imath`, `[dotless i]`},
+	{`\jmath`, `[dotless j]`},
+	{`\Alpha`, `Alpha`},
+	{`\alpha`, `alpha`},
+	{`\Beta`, `Beta`},
+	{`\beta`, `beta`},
+	{`\Gamma`, `Gamma`},
+	{`\gamma`, `gamma`},
+	{`\Delta`, `Delta`},
+	{`\delta`, `delta`},
+	{`\Epsilon`, `Epsilon`},
+	{`\epsilon`, `epsilon`},
+	{`\varepsilon`, `varepsilon`},
+	{`\Zeta`, `Zeta`},
+	{`\zeta`, `zeta`},
+	{`\Eta`, `Eta`},
+	{`\eta`, `eta`},
+	{`\Theta`, `Theta`},
+	{`\theta`, `theta`},
+	{`\thetasym`, `theta`},
+	{`\vartheta`, `theta`},
+	{`\Iota`, `Iota`},
+	{`\iota`, `iota`},
+	{`\Kappa`, `Kappa`},
+	{`\kappa`, `kappa`},
+	{`\Lambda`, `Lambda`},
+	{`\lambda`, `lambda`},
+	{`\Mu`, `Mu`},
+	{`\mu`, `mu`},
+	{`\nu`, `nu`},
+	{`\Nu`, `Nu`},
+	{`\Xi`, `Xi`},
+	{`\xi`, `xi`},
+	{`\Omicron`, `Omicron`},
+	{`\omicron`, `omicron`},
+	{`\Pi`, `Pi`},
+	{`\pi`, `pi`},
+	{`\Rho`, `Rho`},
+	{`\rho`, `rho`},
+	{`\Sigma`, `Sigma`},
+	{`\sigma`, `sigma`},
+	{`\sigmaf`, `sigma`},
+	{`\varsigma`, `sigma`},
+	{`\Tau`, `Tau`},
+	{`\Upsilon`, `Upsilon`},
+	{`\upsih`, `upsilon`},
+	{`\upsilon`, `upsilon`},
+	{`\Phi`, `Phi`},
+	{`\phi`, `phi`},
+	{`\varphi`, `varphi`},
+	{`\Chi`, `Chi`},
+	{`\chi`, `chi`},
+	{`\acutex`, `'x`},
+	{`\Psi`, `Psi`},
+	{`\psi`, `psi`},
+	{`\tau`, `tau`},
+	{`\Omega`, `Omega`},
+	{`\omega`, `omega`},
+	{`\piv`, `omega-pi`},
+	{`\varpi`, `omega-pi`},
+	{`\partial`, `[partial differential]`},
+	{`\alefsym`, `aleph`},
+	{`\aleph`, `aleph`},
+	{`\gimel`, `gimel`},
+	{`\beth`, `beth`},
+	{`\dalet`, `dalet`},
+	{`\ETH`, `D`},
+	{`\eth`, `dh`},
+	{`\THORN`, `TH`},
+	{`\thorn`, `th`},
+	{`\dots`, `...`},
+	{`\cdots`, `...`},
+	{`\hellip`, `...`},
+	{`\middot`, `.`},
+	{`\iexcl`, `!`},
+	{`\iquest`, `?`},
+	{`\shy`, ``},
+	{`\ndash`, `-`},
+	{`\mdash`, `--`},
+	{`\quot`, `"`},
+	{`\acute`, `'`},
+	{`\ldquo`, `"`},
+	{`\rdquo`, `"`},
+	{`\bdquo`, `"`},
+	{`\lsquo`, "`"},
+	{`\rsquo`, `'`},
+	{`\sbquo`, `,`},
+	{`\laquo`, `<<`},
+	{`\raquo`, `>>`},
+	{`\lsaquo`, `<`},
+	{`\rsaquo`, `>`},
+	{`\circ`, `^`},
+	{`\vert`, `|`},
+	{`\vbar`, `|`},
+	{`\brvbar`, `|`},
+	{`\S`, `paragraph`},
+	{`\sect`, `paragraph`},
+	{`\amp`, `&`},
+	{`\lt`, `<`},
+	{`\gt`, `>`},
+	{`\tilde`, `~`},
+	{`\slash`, `/`},
+	{`\plus`, `+`},
+	{`\under`, `_`},
+	{`\equal`, `=`},
+	{`\asciicirc`, `^`},
+	{`\dagger`, `[dagger]`},
+	{`\dag`, `[dagger]`},
+	{`\Dagger`, `[doubledagger]`},
+	{`\ddag`, `[doubledagger]`},
+	{`\nbsp`, ` `},
+	{`\ensp`, ` `},
+	{`\emsp`, `  `},
+	{`\thinsp`, ` `},
+	{`\curren`, `curr.`},
+	{`\cent`, `cent`},
+	{`\pound`, `pound`},
+	{`\yen`, `yen`},
+	{`\euro`, `EUR`},
+	{`\EUR`, `EUR`},
+	{`\dollar`, `$`},
+	{`\USD`, `$`},
+	{`\copy`, `(c)`},
+	{`\reg`, `(r)`},
+	{`\trade`, `TM`},
+	{`\minus`, `-`},
+	{`\pm`, `+-`},
+	{`\plusmn`, `+-`},
+	{`\times`, `*`},
+	{`\frasl`, `/`},
+	{`\colon`, `:`},
+	{`\div`, `/`},
+	{`\frac12`, ` 1/2`},
+	{`\frac14`, ` 1/4`},
+	{`\frac34`, ` 3/4`},
+	{`\permil`, `per thousand`},
+	{`\sup1`, `^1`},
+	{`\sup2`, `^2`},
+	{`\sup3`, `^3`},
+	{`\radic`, `[square root]`},
+	{`\sum`, `[sum]`},
+	{`\prod`, `[product]`},
+	{`\micro`, `micro`},
+	{`\macr`, `[macron]`},
+	{`\deg`, `degree`},
+	{`\prime`, `'`},
+	{`\Prime`, `''`},
+	{`\infin`, `[infinity]`},
+	{`\infty`, `[infinity]`},
+	{`\prop`, `[proportional to]`},
+	{`\propto`, `[proportional to]`},
+	{`\not`, `[angled dash]`},
+	{`\neg`, `[angled dash]`},
+	{`\land`, `[logical and]`},
+	{`\wedge`, `[logical and]`},
+	{`\lor`, `[logical or]`},
+	{`\vee`, `[logical or]`},
+	{`\cap`, `[intersection]`},
+	{`\cup`, `[union]`},
+	{`\smile`, `[cup product]`},
+	{`\frown`, `[Cap product]`},
+	{`\int`, `[integral]`},
+	{`\therefore`, `[therefore]`},
+	{`\there4`, `[therefore]`},
+	{`\because`, `[because]`},
+	{`\sim`, `~`},
+	{`\cong`, `[approx. equal to]`},
+	{`\simeq`, `[approx. equal to]`},
+	{`\asymp`, `[almost equal to]`},
+	{`\approx`, `[almost equal to]`},
+	{`\ne`, `[not equal to]`},
+	{`\neq`, `[not equal to]`},
+	{`\equiv`, `[identical to]`},
+	{`\triangleq`, `[defined to]`},
+	{`\le`, `<=`},
+	{`\leq`, `<=`},
+	{`\ge`, `>=`},
+	{`\geq`, `>=`},
+	{`\lessgtr`, `[less than or greater than]`},
+	{`\lesseqgtr`, `[less than or equal or greater than or equal]`},
+	{`\ll`, `<<`},
+	{`\Ll`, `<<<`},
+	{`\lll`, `<<<`},
+	{`\gg`, `>>`},
+	{`\Gg`, `>>>`},
+	{`\ggg`, `>>>`},
+	{`\prec`, `[precedes]`},
+	{`\preceq`, `[precedes or equal]`},
+	{`\preccurlyeq`, `[precedes or equal]`},
+	{`\succ`, `[succeeds]`},
+	{`\succeq`, `[succeeds or equal]`},
+	{`\succcurlyeq`, `[succeeds or equal]`},
+	{`\sub`, `[subset of]`},
+	{`\subset`, `[subset of]`},
+	{`\sup`, `[superset of]`},
+	{`\supset`, `[superset of]`},
+	{`\nsub`, `[not a subset of]`},
+	{`\sube`, `[subset of or equal to]`},
+	{`\nsup`, `[not a superset of]`},
+	{`\supe`, `[superset of or equal to]`},
+	{`\setminus`, `\`},
+	{`\forall`, `[for all]`},
+	{`\exist`, `[there exists]`},
+	{`\exists`, `[there exists]`},
+	{`\nexist`, `[there does not exists]`},
+	{`\nexists`, `[there does not exists]`},
+	{`\empty`, `[empty set]`},
+	{`\emptyset`, `[empty set]`},
+	{`\isin`, `[element of]`},
+	{`\in`, `[element of]`},
+	{`\notin`, `[not an element of]`},
+	{`\ni`, `[contains as member]`},
+	{`\nabla`, `[nabla]`},
+	{`\ang`, `[angle]`},
+	{`\angle`, `[angle]`},
+	{`\perp`, `[up tack]`},
+	{`\parallel`, `||`},
+	{`\sdot`, `[dot]`},
+	{`\cdot`, `[dot]`},
+	{`\lceil`, `[left ceiling]`},
+	{`\rceil`, `[right ceiling]`},
+	{`\lfloor`, `[left floor]`},
+	{`\rfloor`, `[right floor]`},
+	{`\lang`, `<`},
+	{`\rang`, `>`},
+	{`\langle`, `<`},
+	{`\rangle`, `>`},
+	{`\hbar`, `hbar`},
+	{`\mho`, `mho`},
+	{`\larr`, `<-`},
+	{`\leftarrow`, `<-`},
+	{`\gets`, `<-`},
+	{`\lArr`, `<=`},
+	{`\Leftarrow`, `<=`},
+	{`\uarr`, `^`},
+	{`\uparrow`, `^`},
+	{`\uArr`, `^`},
+	{`\Uparrow`, `^`},
+	{`\rarr`, `->`},
+	{`\to`, `->`},
+	{`\rightarrow`, `->`},
+	{`\rArr`, `=>`},
+	{`\Rightarrow`, `=>`},
+	{`\darr`, `v`},
+	{`\downarrow`, `v`},
+	{`\dArr`, `v`},
+	{`\Downarrow`, `v`},
+	{`\harr`, `<->`},
+	{`\leftrightarrow`, `<->`},
+	{`\hArr`, `<=>`},
+	{`\Leftrightarrow`, `<=>`},
+	{`\crarr`, `<-'`},
+	{`\hookleftarrow`, `<-'`},
+	{`\arccos`, `arccos`},
+	{`\arcsin`, `arcsin`},
+	{`\arctan`, `arctan`},
+	{`\arg`, `arg`},
+	{`\cos`, `cos`},
+	{`\cosh`, `cosh`},
+	{`\cot`, `cot`},
+	{`\coth`, `coth`},
+	{`\csc`, `csc`},
+	{`\det`, `det`},
+	{`\dim`, `dim`},
+	{`\exp`, `exp`},
+	{`\gcd`, `gcd`},
+	{`\hom`, `hom`},
+	{`\inf`, `inf`},
+	{`\ker`, `ker`},
+	{`\lg`, `lg`},
+	{`\lim`, `lim`},
+	{`\liminf`, `liminf`},
+	{`\limsup`, `limsup`},
+	{`\ln`, `ln`},
+	{`\log`, `log`},
+	{`\max`, `max`},
+	{`\min`, `min`},
+	{`\Pr`, `Pr`},
+	{`\sec`, `sec`},
+	{`\sin`, `sin`},
+	{`\sinh`, `sinh`},
+	{`\tan`, `tan`},
+	{`\tanh`, `tanh`},
+	{`\bull`, `*`},
+	{`\bullet`, `*`},
+	{`\star`, `*`},
+	{`\lowast`, `*`},
+	{`\ast`, `*`},
+	{`\odot`, `o`},
+	{`\oplus`, `[circled plus]`},
+	{`\otimes`, `[circled times]`},
+	{`\check`, `[checkmark]`},
+	{`\checkmark`, `[checkmark]`},
+	{`\para`, `[pilcrow]`},
+	{`\ordf`, `_a_`},
+	{`\ordm`, `_o_`},
+	{`\cedil`, `[cedilla]`},
+	{`\oline`, `[overline]`},
+	{`\uml`, `[diaeresis]`},
+	{`\zwnj`, ``},
+	{`\zwj`, ``},
+	{`\lrm`, ``},
+	{`\rlm`, ``},
+	{`\smiley`, `:-)`},
+	{`\blacksmile`, `:-)`},
+	{`\sad`, `:-(`},
+	{`\frowny`, `:-(`},
+	{`\clubs`, `[clubs]`},
+	{`\clubsuit`, `[clubs]`},
+	{`\spades`, `[spades]`},
+	{`\spadesuit`, `[spades]`},
+	{`\hearts`, `[hearts]`},
+	{`\heartsuit`, `[heartsuit]`},
+	{`\diams`, `[diamonds]`},
+	{`\diamondsuit`, `[diamonds]`},
+	{`\diamond`, `[diamond]`},
+	{`\Diamond`, `[diamond]`},
+	{`\loz`, `[lozenge]`},
+}
@@ -0,0 +1,171 @@
+package org
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Backlink is a link from a file of a Workspace to a file or headline of the Workspace.
+type Backlink struct {
+	Source  WorkspaceTarget // Source is the headline containing the link - or the file if the link is not below a headline.
+	Target  WorkspaceTarget // Target is the linked file or headline.
+	Link    RegularLink
+	Context Node // Context is the paragraph containing the link - or the link itself if it is not part of a paragraph.
+}
+
+// LinkGraph is the graph of the links between the files and headlines of a Workspace (see Workspace.Graph).
+type LinkGraph struct {
+	Nodes []LinkGraphNode `json:"nodes"`
+	Edges []LinkGraphEdge `json:"edges"`
+}
+
+type LinkGraphNode struct {
+	ID    string `json:"id"` // ID is the key of the file or headline (see WorkspaceTarget.Key).
+	Path  string `json:"path"`
+	Title string `json:"title"`
+}
+
+type LinkGraphEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Count  int    `json:"count"` // Count is the number of links from the source to the target.
+}
+
+// linkCollector is a Visitor that collects the links of a document of a workspace into Backlinks.
+type linkCollector struct {
+	*Workspace
+	document *Document
+	source   WorkspaceTarget
+	context  Node
+}
+
+// Key returns the path of the file (path#id for headlines) - it identifies the target in the workspace.
+func (t WorkspaceTarget) Key() string {
+	if t.ID == "" {
+		return t.Path
+	}
+	return t.Path + "#" + t.ID
+}
+
+// Backlinks returns the links from other files of the workspace to the file at path (a path of the file system)
+// or one of its headlines.
+func (w *Workspace) Backlinks(path string) []Backlink {
+	key, err := w.key(path)
+	if err != nil {
+		return nil
+	}
+	backlinks := []Backlink{}
+	for _, l := range w.Links {
+		if l.Target.Path == key && l.Source.Path != key {
+			backlinks = append(backlinks, l)
+		}
+	}
+	return backlinks
+}
+
+// Graph returns the link graph of the workspace. Its nodes are all files of the workspace and the headlines
+// that are the source or target of a link. Multiple links between the same nodes are merged into one edge.
+func (w *Workspace) Graph() LinkGraph {
+	g, nodes, edges := LinkGraph{[]LinkGraphNode{}, []LinkGraphEdge{}}, map[string]bool{}, map[[2]string]int{}
+	addNode := func(t WorkspaceTarget) {
+		if key := t.Key(); !nodes[key] {
+			title := t.Path
+			if t.ID != "" {
+				title = strings.TrimSpace(String(t.Title...))
+			} else if w.Titles[t.Path] != "" {
+				title = w.Titles[t.Path]
+			}
+			nodes[key] = true
+			g.Nodes = append(g.Nodes, LinkGraphNode{key, t.Path, title})
+		}
+	}
+	for _, key := range w.sortedKeys() {
+		addNode(WorkspaceTarget{Path: key})
+	}
+	for _, l := range w.Links {
+		addNode(l.Source)
+		addNode(l.Target)
+		edge := [2]string{l.Source.Key(), l.Target.Key()}
+		if edges[edge] == 0 {
+			g.Edges = append(g.Edges, LinkGraphEdge{edge[0], edge[1], 0})
+		}
+		edges[edge]++
+	}
+	for i, e := range g.Edges {
+		g.Edges[i].Count = edges[[2]string{e.Source, e.Target}]
+	}
+	return g
+}
+
+// DOT returns the graph in the DOT language of Graphviz.
+func (g LinkGraph) DOT() string {
+	b := &strings.Builder{}
+	b.WriteString("digraph links {\n")
+	for _, n := range g.Nodes {
+		fmt.Fprintf(b, "  %s [label=%s];\n", strconv.Quote(n.ID), strconv.Quote(n.Title))
+	}
+	for _, e := range g.Edges {
+		if e.Count > 1 {
+			fmt.Fprintf(b, "  %s -> %s [weight=%d];\n", strconv.Quote(e.Source), strconv.Quote(e.Target), e.Count)
+		} else {
+			fmt.Fprintf(b, "  %s -> %s;\n", strconv.Quote(e.Source), strconv.Quote(e.Target))
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// collectLinks collects the links between the documents of the workspace into Links. The links of the documents
+// have already been resolved by ConfigurationFor, i.e. they are #id links (same file) or file:path.org::#id links.
+func (w *Workspace) collectLinks() {
+	w.Links = []Backlink{}
+	for _, key := range w.sortedKeys() {
+		d := w.Documents[key]
+		file := WorkspaceTarget{Path: key}
+		if title := w.Titles[key]; title != "" {
+			file.Title = []Node{Text{Content: title}}
+		}
+		Walk(linkCollector{w, d, file, nil}, d.Nodes...)
+	}
+}
+
+func (c linkCollector) Visit(n Node) Visitor {
+	switch n := n.(type) {
+	case Headline:
+		c.source = WorkspaceTarget{c.source.Path, n.ID(), withoutSpans(n.Title)}
+	case Paragraph:
+		c.context = n
+	case RegularLink:
+		target, ok := c.resolve(n)
+		if !ok {
+			break
+		}
+		context := c.context
+		if context == nil {
+			context = n
+		}
+		c.Links = append(c.Links, Backlink{c.source, target, withoutSpans([]Node{n})[0].(RegularLink), withoutSpans([]Node{context})[0]})
+	}
+	return c
+}
+
+func (c linkCollector) resolve(l RegularLink) (WorkspaceTarget, bool) {
+	if l.Protocol == "" && (strings.HasPrefix(l.URL, "#") || strings.HasPrefix(l.URL, "*")) {
+		if id, title, ok := c.document.ResolveInternalLink(l); ok {
+			return WorkspaceTarget{c.source.Path, id, withoutSpans(title)}, true
+		}
+		return WorkspaceTarget{}, false
+	}
+	return c.Resolve(c.source.Path, l.URL)
+}
+
+func (w *Workspace) sortedKeys() []string {
+	keys := make([]string, 0, len(w.Documents))
+	for key := range w.Documents {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,80 @@
+package org
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBacklinks(t *testing.T) {
+	root := t.TempDir()
+	w := newTestWorkspace(t, root, map[string]string{
+		"a.org": strings.Join([]string{
+			"#+TITLE: A",
+			"intro links to [[file:b.org]]",
+			"* Heading",
+			":PROPERTIES:",
+			":ID: 42",
+			":END:",
+			"- see [[file:b.org::*Target][the target]]",
+			"- and [[*Other]]",
+			"* Other",
+			"* See [[id:43]]",
+		}, "\n"),
+		"b.org": strings.Join([]string{
+			"* Target",
+			":PROPERTIES:",
+			":ID: 43",
+			":END:",
+			"back to [[id:42]] and [[id:42][again]] and [[https://example.com]]",
+		}, "\n"),
+	})
+	actual := []string{}
+	for _, l := range w.Links {
+		actual = append(actual, l.Source.Key()+" -> "+l.Target.Key()+": "+strings.TrimSpace(String(l.Context)))
+	}
+	expected := []string{
+		"a.org -> b.org: intro links to [[file:b.org]]",
+		"a.org#headline-1 -> b.org#headline-1: see [[file:b.org::#headline-1][the target]]",
+		"a.org#headline-1 -> a.org#headline-2: and [[*Other]]",
+		"a.org#headline-3 -> b.org#headline-1: [[file:b.org::#headline-1][Target]]",
+		"b.org#headline-1 -> a.org#headline-1: back to [[file:a.org::#headline-1][Heading]] and [[file:a.org::#headline-1][again]] and [[https://example.com]]",
+		"b.org#headline-1 -> a.org#headline-1: back to [[file:a.org::#headline-1][Heading]] and [[file:a.org::#headline-1][again]] and [[https://example.com]]",
+	}
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("links:\n%s", diff(strings.Join(actual, "\n"), strings.Join(expected, "\n")))
+	}
+
+	backlinks := w.Backlinks(filepath.Join(root, "b.org"))
+	if len(backlinks) != 3 || backlinks[0].Target.ID != "" || backlinks[1].Link.URL != "file:b.org::#headline-1" {
+		t.Errorf("bad backlinks of b.org: %v", backlinks)
+	}
+
+	g := w.Graph()
+	expectedDOT := strings.Join([]string{
+		`digraph links {`,
+		`  "a.org" [label="A"];`,
+		`  "b.org" [label="b.org"];`,
+		`  "a.org#headline-1" [label="Heading"];`,
+		`  "b.org#headline-1" [label="Target"];`,
+		`  "a.org#headline-2" [label="Other"];`,
+		`  "a.org#headline-3" [label="See [[file:b.org::#headline-1][Target]]"];`,
+		`  "a.org" -> "b.org";`,
+		`  "a.org#headline-1" -> "b.org#headline-1";`,
+		`  "a.org#headline-1" -> "a.org#headline-2";`,
+		`  "a.org#headline-3" -> "b.org#headline-1";`,
+		`  "b.org#headline-1" -> "a.org#headline-1" [weight=2];`,
+		`}`,
+		``,
+	}, "\n")
+	if dot := g.DOT(); dot != expectedDOT {
+		t.Errorf("dot:\n%s", diff(dot, expectedDOT))
+	}
+	bs, err := json.Marshal(g)
+	if err != nil {
+		t.Fatal(err)
+	} else if !strings.Contains(string(bs), `{"source":"b.org#headline-1","target":"a.org#headline-1","count":2}`) {
+		t.Errorf("bad json: %s", bs)
+	}
+}
@@ -12,18 +12,23 @@ type Block struct {
 	Parameters []string
 	Children   []Node
 	Result     Node
+	HeaderArgs []string // HeaderArgs contains the inherited header arguments of SRC blocks (header-args properties) - see ParameterMap.
+	Span       Span
 }
 
 type Result struct {
 	Node Node
+	Span Span
 }
 
 type Example struct {
 	Children []Node
+	Span     Span
 }
 
 type LatexBlock struct {
 	Content []Node
+	Span    Span
 }
 
 var exampleLineRegexp = regexp.MustCompile(`^(\s*):(\s(.*)|\s*$)`)
@@ -75,16 +80,25 @@ func (d *Document) parseBlock(i int, parentStop stopFn) (int, Node) {
 	stop := func(d *Document, i int) bool {
 		return i >= len(d.tokens) || (d.tokens[i].kind == "endBlock" && d.tokens[i].content == name)
 	}
-	block, i := Block{name, parameters, nil, nil}, i+1
+	block, i := Block{name, parameters, nil, nil, nil, Span{}}, i+1
 	if isRawTextBlock(name) {
-		rawText := ""
+		lines, bases := []string{}, []int{}
 		for ; !stop(d, i); i++ {
-			rawText += trim(d.tokens[i].matches[0]) + "\n"
+			line, base := d.trimmedLine(i, trim)
+			lines, bases = append(lines, line), append(bases, base)
+		}
+		rawText, at := strings.Join(append(lines, ""), "\n"), offsetFn(nil)
+		if len(lines) != 0 {
+			at = joinedOffsets(lines, bases)
 		}
 		if name == "EXAMPLE" || (name == "SRC" && len(parameters) >= 1 && parameters[0] == "org") {
-			rawText = exampleBlockEscapeRegexp.ReplaceAllString(rawText, "$1$2$3$4")
+			removed := []int{}
+			for j, m := range exampleBlockEscapeRegexp.FindAllStringSubmatchIndex(rawText, -1) {
+				removed = append(removed, m[5]-j)
+			}
+			rawText, at = exampleBlockEscapeRegexp.ReplaceAllString(rawText, "$1$2$3$4"), at.skip(removed)
 		}
-		block.Children = d.parseRawInline(rawText)
+		block.Children = d.parseRawInline(rawText, at)
 	} else {
 		consumed, nodes := d.parseMany(i, stop)
 		block.Children = nodes
@@ -94,27 +108,36 @@ func (d *Document) parseBlock(i int, parentStop stopFn) (int, Node) {
 		return 0, nil
 	}
 	if name == "SRC" {
+		lang := ""
+		if len(parameters) != 0 {
+			lang = parameters[0]
+		}
+		block.HeaderArgs = d.inheritedHeaderArgs(lang)
 		consumed, result := d.parseSrcBlockResult(i+1, parentStop)
 		block.Result = result
 		i += consumed
 	}
+	block.Span = d.tokenSpan(start, i+1)
 	return i + 1 - start, block
 }
 
 func (d *Document) parseLatexBlock(i int, parentStop stopFn) (int, Node) {
 	t, start := d.tokens[i], i
-	name, rawText, trim := t.content, "", trimIndentUpTo(int(math.Max((float64(d.baseLvl)), float64(t.lvl))))
+	name, lines, bases, trim := t.content, []string{}, []int{}, trimIndentUpTo(int(math.Max((float64(d.baseLvl)), float64(t.lvl))))
 	stop := func(d *Document, i int) bool {
 		return i >= len(d.tokens) || (d.tokens[i].kind == "endLatexBlock" && d.tokens[i].content == name)
 	}
 	for ; !stop(d, i); i++ {
-		rawText += trim(d.tokens[i].matches[0]) + "\n"
+		line, base := d.trimmedLine(i, trim)
+		lines, bases = append(lines, line), append(bases, base)
 	}
 	if i >= len(d.tokens) || d.tokens[i].kind != "endLatexBlock" || d.tokens[i].content != name {
 		return 0, nil
 	}
-	rawText += trim(d.tokens[i].matches[0])
-	return i + 1 - start, LatexBlock{d.parseRawInline(rawText)}
+	line, base := d.trimmedLine(i, trim)
+	lines, bases = append(lines, line), append(bases, base)
+	rawText := strings.Join(lines, "\n")
+	return i + 1 - start, LatexBlock{d.parseRawInline(rawText, joinedOffsets(lines, bases)), d.tokenSpan(start, i+1)}
 }
 
 func (d *Document) parseSrcBlockResult(i int, parentStop stopFn) (int, Node) {
@@ -131,8 +154,11 @@ func (d *Document) parseSrcBlockResult(i int, parentStop stopFn) (int, Node) {
 func (d *Document) parseExample(i int, parentStop stopFn) (int, Node) {
 	example, start := Example{}, i
 	for ; !parentStop(d, i) && d.tokens[i].kind == "example"; i++ {
-		example.Children = append(example.Children, Text{d.tokens[i].content, true})
+		t := d.tokens[i]
+		at := d.lineOffsets(i, t.content)
+		example.Children = append(example.Children, Text{t.content, true, d.inlineSpan(at, 0, len(t.content))})
 	}
+	example.Span = d.tokenSpan(start, i)
 	return i - start, example
 }
 
@@ -141,7 +167,7 @@ func (d *Document) parseResult(i int, parentStop stopFn) (int, Node) {
 		return 0, nil
 	}
 	consumed, node := d.parseOne(i+1, parentStop)
-	return consumed + 1, Result{node}
+	return consumed + 1, Result{node, d.tokenSpan(i, i+consumed+1)}
 }
 
 func trimIndentUpTo(max int) func(string) string {
@@ -179,12 +205,25 @@ func (b Block) ParameterMap() map[string]string {
 		return nil
 	}
 	m := map[string]string{":lang": b.Parameters[0]}
+	for i := 0; i+1 < len(b.HeaderArgs); i += 2 {
+		m[b.HeaderArgs[i]] = b.HeaderArgs[i+1]
+	}
 	for i := 1; i+1 < len(b.Parameters); i += 2 {
 		m[b.Parameters[i]] = b.Parameters[i+1]
 	}
 	return m
 }
 
+// splitHeaderArgs splits header arguments (e.g. ":results output :exports both") into key value pairs.
+func splitHeaderArgs(s string) []string {
+	parameters := []string{}
+	for _, p := range strings.Split(" "+strings.TrimSpace(s), " :")[1:] {
+		kv := strings.SplitN(p+" ", " ", 2)
+		parameters = append(parameters, ":"+kv[0], strings.TrimSpace(kv[1]))
+	}
+	return parameters
+}
+
 func (n Example) String() string    { return String(n) }
 func (n Block) String() string      { return String(n) }
 func (n LatexBlock) String() string { return String(n) }
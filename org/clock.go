@@ -0,0 +1,255 @@
+package org
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Clock struct {
+	Timestamp Timestamp     // Timestamp is the clocked range [start]--[end]. Its End is nil for running clocks.
+	Duration  time.Duration // Duration is the clocked time. It is 0 for running clocks.
+	Span      Span
+}
+
+// LogEntry is a state change or note item of a LOGBOOK drawer, e.g.
+//   - State "DONE"       from "TODO"       [2024-01-01 Mon 10:00]
+//   - Note taken on [2024-01-01 Mon 10:00] \\
+//     some note
+type LogEntry struct {
+	Kind string // Kind is either state or note.
+	From string // From is the previous state of a state change entry.
+	To   string // To is the new state of a state change entry.
+	Time Timestamp
+	Item ListItem // Item is the list item the entry was parsed from.
+	Span Span
+}
+
+// ClockReport is the clocked time of a document aggregated per subtree, tag and day. Running clocks are ignored.
+type ClockReport struct {
+	Total   time.Duration
+	Entries []ClockReportEntry       // Entries contains one entry per headline with clocked time in its subtree, in document order.
+	Tags    map[string]time.Duration // Tags is the clocked time per tag, including inherited tags (see Headline.EffectiveTags).
+	Days    map[string]time.Duration // Days is the clocked time per day (2006-01-02). Clocks spanning midnight are split.
+}
+
+type ClockReportEntry struct {
+	Headline Headline
+	Own      time.Duration // Own is the time clocked directly in the headline.
+	Total    time.Duration // Total is the time clocked in the subtree of the headline.
+}
+
+var clockRegexp = regexp.MustCompile(`^(\s*)CLOCK:\s+(\[.*?)\s*$`)
+var clockDurationRegexp = regexp.MustCompile(`^=>\s*(\d+):(\d{2})$`)
+var logStateRegexp = regexp.MustCompile(`^State\s+"([^"]*)"\s+from(?:\s+"([^"]*)")?\s+\[`)
+var logNoteRegexp = regexp.MustCompile(`^Note taken on \[`)
+
+func lexClock(line string) (token, bool) {
+	if m := clockRegexp.FindStringSubmatch(line); m != nil {
+		return token{"clock", len(m[1]), m[2], m}, true
+	}
+	return nilToken, false
+}
+
+func (d *Document) parseClock(i int, parentStop stopFn) (int, Node) {
+	content := d.tokens[i].content
+	at := d.lineOffsets(i, content)
+	consumed, node := d.parseTimestamp(content, 0, at)
+	if consumed == 0 {
+		return 0, nil
+	}
+	timestamp := node.(Timestamp)
+	if !timestamp.IsInactive || timestamp.IsTimeRange {
+		return 0, nil
+	}
+	timestamp.Span = d.inlineSpan(at, 0, consumed)
+	clock, rest := Clock{Timestamp: timestamp}, strings.TrimSpace(content[consumed:])
+	if timestamp.End != nil {
+		clock.Duration = timestamp.End.Time.Sub(timestamp.Time)
+		if rest != "" && !clockDurationRegexp.MatchString(rest) {
+			return 0, nil
+		}
+	} else if rest != "" {
+		return 0, nil
+	}
+	clock.Span = d.tokenSpan(i, i+1)
+	return 1, clock
+}
+
+// parseLogEntries replaces the state change and note items of the lists in nodes with LogEntry nodes.
+func parseLogEntries(nodes []Node) []Node {
+	for i, n := range nodes {
+		if l, ok := n.(List); ok && l.Kind == "unordered" {
+			items := make([]Node, len(l.Items))
+			for j, item := range l.Items {
+				items[j] = item
+				if item, ok := item.(ListItem); ok {
+					if entry, ok := parseLogEntry(item); ok {
+						items[j] = entry
+					}
+				}
+			}
+			l.Items = items
+			nodes[i] = l
+		}
+	}
+	return nodes
+}
+
+func parseLogEntry(item ListItem) (LogEntry, bool) {
+	if len(item.Children) == 0 {
+		return LogEntry{}, false
+	}
+	p, ok := item.Children[0].(Paragraph)
+	if !ok {
+		return LogEntry{}, false
+	}
+	entry, text := LogEntry{Item: item, Span: item.Span}, String(p.Children...)
+	if m := logStateRegexp.FindStringSubmatch(text); m != nil {
+		entry.Kind, entry.To, entry.From = "state", m[1], m[2]
+	} else if logNoteRegexp.MatchString(text) {
+		entry.Kind = "note"
+	} else {
+		return LogEntry{}, false
+	}
+	for _, n := range p.Children {
+		if t, ok := n.(Timestamp); ok {
+			entry.Time = t
+			return entry, true
+		}
+	}
+	return LogEntry{}, false
+}
+
+// collectClocks returns the clocks in nodes and in LOGBOOK drawers in nodes.
+func collectClocks(nodes []Node) []Clock {
+	clocks := []Clock{}
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case Clock:
+			clocks = append(clocks, n)
+		case Drawer:
+			if n.Name == "LOGBOOK" {
+				clocks = append(clocks, collectClocks(n.Children)...)
+			}
+		}
+	}
+	return clocks
+}
+
+// ClockedTime returns the time clocked directly in the headline, i.e. excluding its sub headlines.
+func (h Headline) ClockedTime() time.Duration {
+	total := time.Duration(0)
+	for _, c := range h.Clocks {
+		total += c.Duration
+	}
+	return total
+}
+
+// ClockReport aggregates the clocked time of the document per subtree, tag and day.
+func (d *Document) ClockReport() ClockReport {
+	r := ClockReport{Tags: map[string]time.Duration{}, Days: map[string]time.Duration{}}
+	var walk func(nodes []Node) time.Duration
+	walk = func(nodes []Node) time.Duration {
+		total := time.Duration(0)
+		for _, n := range nodes {
+			h, ok := n.(Headline)
+			if !ok {
+				continue
+			}
+			i := len(r.Entries)
+			r.Entries = append(r.Entries, ClockReportEntry{Headline: h, Own: h.ClockedTime()})
+			for _, tag := range h.EffectiveTags() {
+				r.Tags[tag] += r.Entries[i].Own
+			}
+			for _, c := range h.Clocks {
+				r.addDays(c)
+			}
+			subtotal := r.Entries[i].Own + walk(h.Children)
+			if r.Entries[i].Total = subtotal; subtotal == 0 {
+				r.Entries = append(r.Entries[:i], r.Entries[i+1:]...)
+			}
+			total += subtotal
+		}
+		return total
+	}
+	r.Total = walk(d.Nodes)
+	for tag, duration := range r.Tags {
+		if duration == 0 {
+			delete(r.Tags, tag)
+		}
+	}
+	return r
+}
+
+func (r *ClockReport) addDays(c Clock) {
+	if c.Timestamp.End == nil {
+		return
+	}
+	for start, end := c.Timestamp.Time, c.Timestamp.End.Time; start.Before(end); {
+		midnight := time.Date(start.Year(), start.Month(), start.Day()+1, 0, 0, 0, 0, start.Location())
+		if midnight.After(end) {
+			midnight = end
+		}
+		r.Days[start.Format("2006-01-02")] += midnight.Sub(start)
+		start = midnight
+	}
+}
+
+// String returns the report as Org mode tables (per subtree, per tag and per day) in the style of an Org mode clocktable.
+func (r ClockReport) String() string {
+	maxLvl := 1
+	for _, e := range r.Entries {
+		if e.Headline.Lvl > maxLvl {
+			maxLvl = e.Headline.Lvl
+		}
+	}
+	row := func(columns ...string) string { return "| " + strings.Join(columns, " | ") + " |\n" }
+	timeColumns := func(lvl int, duration string) []string {
+		columns := make([]string, maxLvl)
+		columns[lvl-1] = duration
+		return columns
+	}
+	b := strings.Builder{}
+	b.WriteString(row(append([]string{"Headline", "Time"}, make([]string, maxLvl-1)...)...) + "|-\n")
+	b.WriteString(row(append([]string{"*Total time*", "*" + formatClockDuration(r.Total) + "*"}, make([]string, maxLvl-1)...)...) + "|-\n")
+	for _, e := range r.Entries {
+		title := strings.ReplaceAll(strings.TrimSpace(String(e.Headline.Title...)), "|", `\vert{}`)
+		if e.Headline.Lvl > 1 {
+			title = `\_` + strings.Repeat(" ", 2*(e.Headline.Lvl-1)) + title
+		}
+		b.WriteString(row(append([]string{title}, timeColumns(e.Headline.Lvl, formatClockDuration(e.Total))...)...))
+	}
+	for _, t := range []struct {
+		name      string
+		durations map[string]time.Duration
+	}{{"Tag", r.Tags}, {"Day", r.Days}} {
+		if len(t.durations) == 0 {
+			continue
+		}
+		keys := make([]string, 0, len(t.durations))
+		for k := range t.durations {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteString("\n" + row(t.name, "Time") + "|-\n")
+		for _, k := range keys {
+			b.WriteString(row(k, formatClockDuration(t.durations[k])))
+		}
+	}
+	out, err := New().Silent().Parse(strings.NewReader(b.String()), "").Write(NewOrgWriter())
+	if err != nil {
+		return b.String()
+	}
+	return out
+}
+
+func formatClockDuration(d time.Duration) string {
+	minutes := int(d.Round(time.Minute) / time.Minute)
+	return fmt.Sprintf("%d:%02d", minutes/60, minutes%60)
+}
+
+func (n Clock) String() string    { return String(n) }
+func (n LogEntry) String() string { return String(n) }
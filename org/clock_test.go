@@ -0,0 +1,73 @@
+package org
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClockReport(t *testing.T) {
+	f, err := os.Open("./testdata/clocks.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	d := New().Silent().Parse(f, "./testdata/clocks.org")
+	r := d.ClockReport()
+	if r.Total != 16*time.Hour+30*time.Minute {
+		t.Errorf("unexpected total: %s", r.Total)
+	}
+	if r.Tags["client_a"] != 4*time.Hour+15*time.Minute || r.Tags["review"] != 45*time.Minute {
+		t.Errorf("unexpected tags: %v", r.Tags)
+	}
+	if r.Days["2024-01-01"] != time.Hour || r.Days["2024-01-02"] != 2*time.Hour+30*time.Minute {
+		t.Errorf("unexpected days: %v", r.Days)
+	}
+	expected := strings.TrimLeft(`
+| Headline     | Time    |      |
+|--------------+---------+------|
+| *Total time* | *16:30* |      |
+|--------------+---------+------|
+| Project A    | 4:15    |      |
+| \_  Task 1   |         | 0:45 |
+| Project B    | 12:15   |      |
+
+| Tag      | Time |
+|----------+------|
+| client_a | 4:15 |
+| review   | 0:45 |
+
+| Day        | Time  |
+|------------+-------|
+| 2024-01-01 | 1:00  |
+| 2024-01-02 | 2:30  |
+| 2024-01-03 | 0:45  |
+| 2024-01-04 | 12:15 |
+`, "\n")
+	if actual := r.String(); actual != expected {
+		t.Errorf("unexpected clock table:\n%s", diff(actual, expected))
+	}
+}
+
+func TestLogEntries(t *testing.T) {
+	d := New().Silent().Parse(strings.NewReader(`* DONE headline
+:LOGBOOK:
+- State "DONE"       from "TODO"       [2024-01-02 Tue 17:00]
+- Note taken on [2024-01-02 Tue 16:00] \\
+  some note
+- not an entry
+:END:
+`), "")
+	items := d.Nodes[0].(Headline).Children[0].(Drawer).Children[0].(List).Items
+	state, ok := items[0].(LogEntry)
+	if !ok || state.Kind != "state" || state.From != "TODO" || state.To != "DONE" || state.Time.Time.Hour() != 17 {
+		t.Errorf("unexpected state entry: %#v", items[0])
+	}
+	if note, ok := items[1].(LogEntry); !ok || note.Kind != "note" || note.Time.Time.Hour() != 16 {
+		t.Errorf("unexpected note entry: %#v", items[1])
+	}
+	if _, ok := items[2].(ListItem); !ok {
+		t.Errorf("unexpected list item: %#v", items[2])
+	}
+}
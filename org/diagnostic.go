@@ -0,0 +1,78 @@
+package org
+
+import "fmt"
+
+// Severity is the severity of a Diagnostic.
+type Severity int
+
+const (
+	SeverityError Severity = iota + 1
+	SeverityWarning
+	SeverityInfo
+)
+
+// Codes of the diagnostics reported by the parser, the writers and Document.Lint.
+const (
+	CodeParseError                = "parse-error"
+	CodeUnparsableToken           = "unparsable-token"
+	CodeMissingExportOption       = "missing-export-option"
+	CodeBadInclude                = "bad-include"
+	CodeBadSetupFile              = "bad-setup-file"
+	CodeMissingFootnoteDefinition = "missing-footnote-definition"
+	CodeBadMacro                  = "bad-macro"
+	CodeBadHTMLAttributes         = "bad-html-attributes"
+	CodeUnclosedBlock             = "unclosed-block"
+	CodeDuplicateCustomID         = "duplicate-custom-id"
+	CodeBrokenLink                = "broken-link"
+	CodeMisplacedPropertyDrawer   = "misplaced-property-drawer"
+	CodeInvalidTimestamp          = "invalid-timestamp"
+	CodeUnknownExportOption       = "unknown-export-option"
+	CodeUnusedLinkAbbreviation    = "unused-link-abbreviation"
+)
+
+// Diagnostic is a problem found while parsing or writing a document.
+type Diagnostic struct {
+	Severity Severity
+	Code     string // Code identifies the kind of problem (e.g. CodeMissingFootnoteDefinition).
+	Message  string
+	Span     Span // Span is the part of the parse input the problem was found in. It is zero if the problem has no location.
+}
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	case SeverityInfo:
+		return "info"
+	}
+	return fmt.Sprintf("Severity(%d)", int(s))
+}
+
+func (d Diagnostic) String() string {
+	if !d.Span.IsValid() {
+		return fmt.Sprintf("%s: %s [%s]", d.Severity, d.Message, d.Code)
+	}
+	return fmt.Sprintf("%s: %s: %s [%s]", d.Span.Start, d.Severity, d.Message, d.Code)
+}
+
+// addDiagnostic adds a diagnostic to the document and prints its message to the Log of the document.
+func (d *Document) addDiagnostic(severity Severity, code string, span Span, format string, args ...interface{}) {
+	d.Diagnostics = append(d.Diagnostics, d.diagnostic(severity, code, span, format, args...))
+}
+
+// diagnostic returns a new diagnostic and prints its message to the Log of the document - prefixed with the path
+// of the document and the position of the problem if known.
+// It is used by writers, which collect their diagnostics themselves as documents are not modified by writing them.
+func (d *Document) diagnostic(severity Severity, code string, span Span, format string, args ...interface{}) Diagnostic {
+	diagnostic := Diagnostic{severity, code, fmt.Sprintf(format, args...), span}
+	if d == nil || d.Log == nil {
+		return diagnostic
+	} else if d.Path != "" && span.IsValid() {
+		d.Log.Printf("%s:%s: %s", d.Path, span.Start, diagnostic.Message)
+	} else {
+		d.Log.Print(diagnostic.Message)
+	}
+	return diagnostic
+}
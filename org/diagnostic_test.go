@@ -0,0 +1,47 @@
+package org
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestDiagnostics(t *testing.T) {
+	logs := &bytes.Buffer{}
+	c := New()
+	c.Log = log.New(logs, "", 0)
+	c.ReadFile = func(string) ([]byte, error) { return nil, fmt.Errorf("not found") }
+	d := c.Parse(strings.NewReader("#+INCLUDE: \"missing.org\" src go\n\nreference[fn:missing]\n"), "./test.org")
+	w := NewHTMLWriter()
+	if _, err := d.Write(w); err != nil {
+		t.Fatal(err)
+	}
+	expected := []struct {
+		severity Severity
+		code     string
+		position string
+	}{
+		{SeverityError, CodeBadInclude, "1:1"},
+		{SeverityWarning, CodeMissingFootnoteDefinition, "3:10"},
+	}
+	if len(d.Diagnostics) != 1 || len(w.Diagnostics) != 1 {
+		t.Fatalf("got %v and %v, expected 1 parse and 1 writer diagnostic", d.Diagnostics, w.Diagnostics)
+	}
+	for i, diagnostic := range append(d.Diagnostics, w.Diagnostics...) {
+		e := expected[i]
+		if diagnostic.Severity != e.severity || diagnostic.Code != e.code || diagnostic.Span.Start.String() != e.position {
+			t.Errorf("got %s, expected %s %s at %s", diagnostic, e.severity, e.code, e.position)
+		}
+		if !strings.Contains(logs.String(), diagnostic.Message) {
+			t.Errorf("diagnostic %q was not logged", diagnostic.Message)
+		}
+	}
+	if actual, expected := w.Diagnostics[0].String(), "3:10: warning: Missing footnote definition for [fn:missing] (#1) [missing-footnote-definition]"; actual != expected {
+		t.Errorf("got %q, expected %q", actual, expected)
+	}
+	if _, err := d.Write(NewHTMLWriter()); err != nil || len(d.Diagnostics) != 1 {
+		t.Errorf("writing modified the diagnostics of the document: %v (%v)", d.Diagnostics, err)
+	}
+}
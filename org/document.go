@@ -30,6 +30,9 @@ type Configuration struct {
 	Log                 *log.Logger                           // Log is used to print warnings during parsing.
 	ReadFile            func(filename string) ([]byte, error) // ReadFile is used to read e.g. #+INCLUDE files.
 	ResolveLink         func(protocol string, description []Node, link string) Node
+	Lossless            bool // Lossless keeps the parse input so that OrgWriter only re-renders changed nodes and otherwise reproduces the input byte for byte.
+	// TagsExcludeFromInheritance contains tags that are not inherited by sub headlines. See org-tags-exclude-from-inheritance.
+	TagsExcludeFromInheritance []string
 }
 
 // Document contains the parsing results and a pointer to the Configuration.
@@ -37,7 +40,13 @@ type Document struct {
 	*Configuration
 	Path           string // Path of the file containing the parse input - used to resolve relative paths during parsing (e.g. INCLUDE).
 	tokens         []token
+	lines          [][2]int // lines[i] is the byte range of input line i, excluding the line terminator.
+	offsets        []int    // offsets[i] is the byte offset d.tokens[i] was lexed from - see retokenize.
+	source         string   // source is the parse input if Configuration.Lossless is set.
+	sourceNodes    map[sourceKey]Node
 	baseLvl        int
+	radioTargets   []string        // radioTargets contains the names of all <<<radio targets>>> - see collectRadioTargets.
+	targets        map[string]bool // targets contains the names of all <<targets>> and <<<radio targets>>> - see collectTargets.
 	Macros         map[string]string
 	Links          map[string]string
 	Nodes          []Node
@@ -45,6 +54,7 @@ type Document struct {
 	Outline        Outline           // Outline is a Table Of Contents for the document and contains all sections (headline + content).
 	BufferSettings map[string]string // Settings contains all settings that were parsed from keywords.
 	Error          error
+	Diagnostics    []Diagnostic // Diagnostics contains the problems found while parsing the document - see the writers for problems found while writing.
 }
 
 // Node represents a parsed node of the document.
@@ -75,6 +85,7 @@ var lexFns = []lexFn{
 	lexFootnoteDefinition,
 	lexExample,
 	lexLatexBlock,
+	lexClock,
 	lexText,
 }
 
@@ -90,12 +101,13 @@ func New() *Configuration {
 		DefaultSettings: map[string]string{
 			"TODO":         "TODO | DONE",
 			"EXCLUDE_TAGS": "noexport",
-			"OPTIONS":      "toc:t <:t e:t f:t pri:t todo:t tags:t title:t ealb:nil",
+			"SELECT_TAGS":  "export",
+			"OPTIONS":      "toc:t <:t e:t f:t p:nil c:nil pri:t todo:t tags:t title:t ealb:nil num:t",
 		},
 		Log:      log.New(os.Stderr, "go-org: ", 0),
 		ReadFile: ioutil.ReadFile,
 		ResolveLink: func(protocol string, description []Node, link string) Node {
-			return RegularLink{protocol, description, link, false}
+			return RegularLink{protocol, description, link, false, Span{}}
 		},
 	}
 }
@@ -131,7 +143,7 @@ func (c *Configuration) Parse(input io.Reader, path string) (d *Document) {
 	outlineSection := &Section{}
 	d = &Document{
 		Configuration:  c,
-		Outline:        Outline{outlineSection, outlineSection, 0},
+		Outline:        Outline{outlineSection, outlineSection, 0, false},
 		BufferSettings: map[string]string{},
 		NamedNodes:     map[string]Node{},
 		Links:          map[string]string{},
@@ -142,13 +154,27 @@ func (c *Configuration) Parse(input io.Reader, path string) (d *Document) {
 		if recovered := recover(); recovered != nil {
 			d.Error = fmt.Errorf("could not parse input: %v", recovered)
 		}
+		if d.Error != nil {
+			d.Diagnostics = append(d.Diagnostics, Diagnostic{SeverityError, CodeParseError, d.Error.Error(), Span{}})
+		}
 	}()
 	if d.tokens != nil {
 		d.Error = fmt.Errorf("parse was called multiple times")
 	}
+	source := &strings.Builder{}
+	if c.Lossless {
+		input = io.TeeReader(input, source)
+	}
 	d.tokenize(input)
+	d.collectFileSettings()
+	d.collectRadioTargets()
 	_, nodes := d.parseMany(0, func(d *Document, i int) bool { return i >= len(d.tokens) })
 	d.Nodes = nodes
+	d.collectTargets()
+	if c.Lossless {
+		d.source = source.String()
+		d.recordSourceNodes()
+	}
 	return d
 }
 
@@ -159,8 +185,17 @@ func (c *Configuration) Silent() *Configuration {
 }
 
 func (d *Document) tokenize(input io.Reader) {
-	d.tokens = []token{}
+	d.tokens, d.lines, d.offsets = []token{}, [][2]int{}, []int{}
+	offset := 0
 	scanner := bufio.NewScanner(input)
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, line, err := bufio.ScanLines(data, atEOF)
+		if line != nil {
+			d.lines, d.offsets = append(d.lines, [2]int{offset, offset + len(line)}), append(d.offsets, offset)
+			offset += advance
+		}
+		return advance, line, err
+	})
 	for scanner.Scan() {
 		d.tokens = append(d.tokens, tokenize(scanner.Text()))
 	}
@@ -185,11 +220,14 @@ func (d *Document) Get(key string) string {
 // - < (export timestamps)
 // - e (export org entities)
 // - f (export footnotes)
+// - p (export planning info - SCHEDULED, DEADLINE and CLOSED timestamps)
+// - c (export CLOCK lines)
 // - title (export title)
 // - toc (export table of content. an int limits the included org headline lvl)
 // - todo (export headline todo status)
 // - pri (export headline priority)
 // - tags (export headline tags)
+// - num (export headline section numbers - only supported by TextWriter and LaTeXWriter)
 // - ealb (non-standard) (export with east asian line breaks / ignore line breaks between multi-byte characters)
 // see https://orgmode.org/manual/Export-Settings.html for more information
 func (d *Document) GetOption(key string) string {
@@ -207,7 +245,7 @@ func (d *Document) GetOption(key string) string {
 	}
 	if value == "" {
 		value = "nil"
-		d.Log.Printf("Missing value for export option %s", key)
+		d.addDiagnostic(SeverityWarning, CodeMissingExportOption, Span{}, "Missing value for export option %s", key)
 	}
 	return value
 }
@@ -240,12 +278,20 @@ func (d *Document) parseOne(i int, stop stopFn) (consumed int, node Node) {
 		consumed, node = d.parseHeadline(i, stop)
 	case "footnoteDefinition":
 		consumed, node = d.parseFootnoteDefinition(i, stop)
+	case "clock":
+		consumed, node = d.parseClock(i, stop)
 	}
 
 	if consumed != 0 {
 		return consumed, node
 	}
-	d.Log.Printf("Could not parse token %#v in file %s: Falling back to treating it as plain text.", d.tokens[i], d.Path)
+	if d.tokens[i].kind == "beginBlock" {
+		d.addDiagnostic(SeverityError, CodeUnclosedBlock, d.tokenSpan(i, i+1),
+			"Missing #+END_%s for #+BEGIN_%s: Falling back to treating it as plain text.", d.tokens[i].content, d.tokens[i].content)
+	} else {
+		d.addDiagnostic(SeverityWarning, CodeUnparsableToken, d.tokenSpan(i, i+1),
+			"Could not parse token %#v: Falling back to treating it as plain text.", d.tokens[i])
+	}
 	m := plainTextRegexp.FindStringSubmatch(d.tokens[i].matches[0])
 	d.tokens[i] = token{"text", len(m[1]), m[2], m}
 	return d.parseOne(i, stop)
@@ -264,9 +310,12 @@ func (d *Document) parseMany(i int, stop stopFn) (int, []Node) {
 func (d *Document) addHeadline(headline *Headline) int {
 	current := &Section{Headline: headline}
 	d.Outline.last.add(current)
-	if !headline.IsExcluded(d) {
+	if !headline.IsComment && !headline.hasTag(d, "EXCLUDE_TAGS") {
 		d.Outline.count++
 	}
+	if headline.hasTag(d, "SELECT_TAGS") {
+		d.Outline.selected = true
+	}
 	d.Outline.last = current
 	return d.Outline.count
 }
@@ -8,10 +8,12 @@ import (
 type Drawer struct {
 	Name     string
 	Children []Node
+	Span     Span
 }
 
 type PropertyDrawer struct {
 	Properties [][]string
+	Span       Span
 }
 
 var beginDrawerRegexp = regexp.MustCompile(`^(\s*):(\S+):\s*$`)
@@ -46,7 +48,8 @@ func (d *Document) parseDrawer(i int, parentStop stopFn) (int, Node) {
 		i += consumed
 		drawer.Children = append(drawer.Children, nodes...)
 		if i < len(d.tokens) && d.tokens[i].kind == "beginDrawer" {
-			p := Paragraph{[]Node{Text{":" + d.tokens[i].content + ":", false}}}
+			span := d.tokenSpan(i, i+1)
+			p := Paragraph{[]Node{Text{":" + d.tokens[i].content + ":", false, span}}, span}
 			drawer.Children = append(drawer.Children, p)
 			i++
 		} else {
@@ -56,6 +59,10 @@ func (d *Document) parseDrawer(i int, parentStop stopFn) (int, Node) {
 	if i < len(d.tokens) && d.tokens[i].kind == "endDrawer" {
 		i++
 	}
+	if name == "LOGBOOK" {
+		drawer.Children = parseLogEntries(drawer.Children)
+	}
+	drawer.Span = d.tokenSpan(start, i)
 	return i - start, drawer
 }
 
@@ -78,6 +85,7 @@ func (d *Document) parsePropertyDrawer(i int, parentStop stopFn) (int, Node) {
 	} else {
 		return 0, nil
 	}
+	drawer.Span = d.tokenSpan(start, i)
 	return i - start, drawer
 }
 
@@ -93,5 +101,52 @@ func (d *PropertyDrawer) Get(key string) (string, bool) {
 	return "", false
 }
 
+// InheritedProperty returns the value of the property key of the headline of section s. Properties that the headline does
+// not define are inherited from its ancestors and #+PROPERTY keywords. Values of key+ properties are appended to the value of key.
+func (d *Document) InheritedProperty(s *Section, key string) (string, bool) {
+	key = strings.ToUpper(key)
+	if s == nil || s.Headline == nil {
+		return accumulateProperty(d.fileProperties(), key, "", false)
+	}
+	value, ok := d.InheritedProperty(s.Parent, key)
+	if s.Headline.Properties == nil {
+		return value, ok
+	}
+	return accumulateProperty(s.Headline.Properties.Properties, key, value, ok)
+}
+
+// fileProperties returns the properties defined by #+PROPERTY keywords.
+func (d *Document) fileProperties() [][]string {
+	properties := [][]string{}
+	for _, line := range strings.Split(d.Get("PROPERTY"), "\n") {
+		if kv := strings.SplitN(strings.TrimSpace(line), " ", 2); kv[0] != "" {
+			properties = append(properties, []string{strings.ToUpper(kv[0]), strings.TrimSpace(strings.Join(kv[1:], ""))})
+		}
+	}
+	return properties
+}
+
+// inheritedHeaderArgs returns the header arguments for SRC blocks of language lang in the current section.
+func (d *Document) inheritedHeaderArgs(lang string) []string {
+	args := []string{}
+	for _, key := range []string{"HEADER-ARGS", "HEADER-ARGS:" + lang} {
+		if v, ok := d.InheritedProperty(d.Outline.last, key); ok {
+			args = append(args, splitHeaderArgs(v)...)
+		}
+	}
+	return args
+}
+
+func accumulateProperty(properties [][]string, key, value string, ok bool) (string, bool) {
+	for _, kv := range properties {
+		if kv[0] == key {
+			value, ok = kv[1], true
+		} else if kv[0] == key+"+" {
+			value, ok = strings.TrimSpace(value+" "+kv[1]), true
+		}
+	}
+	return value, ok
+}
+
 func (n Drawer) String() string         { return String(n) }
 func (n PropertyDrawer) String() string { return String(n) }
@@ -0,0 +1,53 @@
+package org
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInheritedProperty(t *testing.T) {
+	d := New().Silent().Parse(strings.NewReader(`#+PROPERTY: var foo=1
+#+PROPERTY: var+ bar=2
+* parent
+:PROPERTIES:
+:VAR+: baz=3
+:owner: alice
+:END:
+** child
+:PROPERTIES:
+:var: qux=4
+:var+: quux=5
+:END:
+** sibling
+`), "")
+	parent := d.Outline.Children[0]
+	child, sibling := parent.Children[0], parent.Children[1]
+	for _, c := range []struct {
+		section  *Section
+		key      string
+		expected string
+		ok       bool
+	}{
+		{d.Outline.Section, "var", "foo=1 bar=2", true},
+		{parent, "var", "foo=1 bar=2 baz=3", true},
+		{child, "VAR", "qux=4 quux=5", true},
+		{sibling, "var", "foo=1 bar=2 baz=3", true},
+		{sibling, "owner", "alice", true},
+		{sibling, "missing", "", false},
+	} {
+		if value, ok := d.InheritedProperty(c.section, c.key); value != c.expected || ok != c.ok {
+			t.Errorf("%s of %v: got %q %v, expected %q %v", c.key, c.section.Headline, value, ok, c.expected, c.ok)
+		}
+	}
+}
+
+func TestPropertyAfterFirstBlock(t *testing.T) {
+	d := New().Silent().Parse(strings.NewReader(`#+BEGIN_SRC sh
+echo "not exported"
+#+END_SRC
+#+PROPERTY: header-args :exports none
+`), "")
+	if exports := d.Nodes[0].(Block).ParameterMap()[":exports"]; exports != "none" {
+		t.Errorf("expected #+PROPERTY after the block to apply: %q", exports)
+	}
+}
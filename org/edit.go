@@ -0,0 +1,335 @@
+package org
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// StatusChange configures Headline.SetStatus.
+type StatusChange struct {
+	Time   time.Time // Time is the time of the change. The current time is used if Time is zero.
+	Closed bool      // Closed adds a CLOSED timestamp when changing to a done state and removes it when changing to a not done state.
+	Log    bool      // Log adds a state change entry to the LOGBOOK drawer of the headline.
+	Note   string    // Note is added to the state change entry if Log is set.
+}
+
+// SetStatus returns a copy of the headline with its status set to status (e.g. DONE) - or "" to remove the status.
+// An error is returned if status is not one of the TODO keywords of the document.
+func (h Headline) SetStatus(d *Document, status string, change StatusChange) (Headline, error) {
+	keywords := d.TodoKeywords()
+	if status != "" && !containsString(keywords.Keywords(), status) {
+		return h, fmt.Errorf("unknown TODO keyword %q", status)
+	}
+	if change.Time.IsZero() {
+		change.Time = time.Now()
+	}
+	from, stamp := h.Status, Timestamp{Time: change.Time, IsInactive: true}
+	h.Status = status
+	if change.Closed && keywords.IsDone(status) && !keywords.IsDone(from) {
+		h.Closed = &stamp
+	} else if change.Closed && !keywords.IsDone(status) {
+		h.Closed = nil
+	}
+	if change.Log {
+		entry := fmt.Sprintf("- State %-12s from %-12s %s", `"`+status+`"`, `"`+from+`"`, String(stamp))
+		if change.Note != "" {
+			entry += ` \\` + "\n  " + strings.Replace(change.Note, "\n", "\n  ", -1)
+		}
+		h = h.addLogEntry(d, entry)
+	}
+	return h, nil
+}
+
+// SetProperty returns a copy of the headline with the property key set to value.
+func (h Headline) SetProperty(key, value string) Headline {
+	key, drawer := strings.ToUpper(key), PropertyDrawer{}
+	if h.Properties != nil {
+		drawer = *h.Properties
+	}
+	properties, found := [][]string{}, false
+	for _, kv := range drawer.Properties {
+		if kv[0] == key {
+			kv, found = []string{key, value}, true
+		}
+		properties = append(properties, kv)
+	}
+	if !found {
+		properties = append(properties, []string{key, value})
+	}
+	drawer.Properties, h.Properties = properties, &drawer
+	return h
+}
+
+// DeleteProperty returns a copy of the headline without the property key.
+// The property drawer is removed if it does not contain any other properties.
+func (h Headline) DeleteProperty(key string) Headline {
+	if h.Properties == nil {
+		return h
+	}
+	key, drawer, properties := strings.ToUpper(key), *h.Properties, [][]string{}
+	for _, kv := range drawer.Properties {
+		if kv[0] != key {
+			properties = append(properties, kv)
+		}
+	}
+	if len(properties) == 0 {
+		h.Properties = nil
+	} else {
+		drawer.Properties, h.Properties = properties, &drawer
+	}
+	return h
+}
+
+// AddTag returns a copy of the headline with tag added to its tags.
+func (h Headline) AddTag(tag string) Headline {
+	if !containsString(h.Tags, tag) {
+		h.Tags = append(append([]string{}, h.Tags...), tag)
+	}
+	return h
+}
+
+// RemoveTag returns a copy of the headline with tag removed from its tags.
+func (h Headline) RemoveTag(tag string) Headline {
+	tags := []string{}
+	for _, t := range h.Tags {
+		if t != tag {
+			tags = append(tags, t)
+		}
+	}
+	h.Tags = tags
+	return h
+}
+
+// Promote returns a copy of the headline with its level and the levels of its sub headlines decreased by one.
+// Top level headlines are returned unchanged.
+func (h Headline) Promote() Headline {
+	if h.Lvl == 1 {
+		return h
+	}
+	return h.shiftLevel(-1)
+}
+
+// Demote returns a copy of the headline with its level and the levels of its sub headlines increased by one.
+func (h Headline) Demote() Headline { return h.shiftLevel(1) }
+
+// EditHeadline replaces the first headline (in document order) that matches with the result of edit.
+// Like all editing methods of Document, it returns false if no headline matches and otherwise
+// updates the Outline and the nesting of headlines to match their (possibly changed) levels.
+func (d *Document) EditHeadline(match func(Headline) bool, edit func(Headline) Headline) bool {
+	return d.editSiblings(match, func(nodes []Node, i int) ([]Node, bool) {
+		nodes[i] = edit(nodes[i].(Headline))
+		return nodes, true
+	})
+}
+
+// InsertHeadline adds h as the last sub headline of the first headline that matches parent.
+// h is added as last top level headline if parent is nil. The levels of h and its sub headlines are adjusted.
+func (d *Document) InsertHeadline(parent func(Headline) bool, h Headline) bool {
+	if parent == nil {
+		d.updateNodes(append(append([]Node{}, d.Nodes...), d.adoptHeadline(h, 1)))
+		return true
+	}
+	return d.EditHeadline(parent, func(p Headline) Headline {
+		p.Children = append(append([]Node{}, p.Children...), d.adoptHeadline(h, p.Lvl+1))
+		return p
+	})
+}
+
+// MoveHeadlineUp swaps the first headline that matches with its previous sibling headline.
+func (d *Document) MoveHeadlineUp(match func(Headline) bool) bool { return d.moveHeadline(match, -1) }
+
+// MoveHeadlineDown swaps the first headline that matches with its next sibling headline.
+func (d *Document) MoveHeadlineDown(match func(Headline) bool) bool { return d.moveHeadline(match, 1) }
+
+// Refile moves the first headline that matches (including its sub headlines) to target (see InsertHeadline).
+// Nothing is moved if no headline of target matches parent.
+func (d *Document) Refile(match func(Headline) bool, target *Document, parent func(Headline) bool) bool {
+	original, h := d.Nodes, Headline{}
+	removed := d.editSiblings(match, func(nodes []Node, i int) ([]Node, bool) {
+		h = nodes[i].(Headline)
+		return append(nodes[:i], nodes[i+1:]...), true
+	})
+	if !removed {
+		return false
+	} else if !target.InsertHeadline(parent, h) {
+		d.updateNodes(original)
+		return false
+	}
+	return true
+}
+
+func (d *Document) moveHeadline(match func(Headline) bool, delta int) bool {
+	return d.editSiblings(match, func(nodes []Node, i int) ([]Node, bool) {
+		for j := i + delta; j >= 0 && j < len(nodes); j += delta {
+			if _, ok := nodes[j].(Headline); ok {
+				nodes[i], nodes[j] = nodes[j], nodes[i]
+				return nodes, true
+			}
+		}
+		return nodes, false
+	})
+}
+
+// editSiblings calls edit with a copy of the siblings of the first headline that matches and its index.
+// The document is updated with the returned siblings unless edit returns false.
+func (d *Document) editSiblings(match func(Headline) bool, edit func([]Node, int) ([]Node, bool)) bool {
+	found, edited := false, false
+	var f func([]Node) []Node
+	f = func(nodes []Node) []Node {
+		for i, n := range nodes {
+			h, ok := n.(Headline)
+			if !ok {
+				continue
+			} else if match(h) {
+				found = true
+				nodes, edited = edit(append([]Node{}, nodes...), i)
+				return nodes
+			} else if children := f(h.Children); found {
+				h.Children = children
+				nodes = append([]Node{}, nodes...)
+				nodes[i] = h
+				return nodes
+			}
+		}
+		return nodes
+	}
+	if nodes := f(d.Nodes); edited {
+		d.updateNodes(nodes)
+	}
+	return edited
+}
+
+// updateNodes sets the nodes of the document after an edit: Headlines are nested according to their levels
+// (i.e. as they would be when parsing the written document) and the Outline is rebuilt.
+func (d *Document) updateNodes(nodes []Node) {
+	outlineSection := &Section{}
+	d.Outline = Outline{outlineSection, outlineSection, 0, false}
+	d.Nodes = d.addSections(nestHeadlines(flattenHeadlines(nodes)))
+	d.collectTargets()
+}
+
+// addSections adds the headlines in nodes to the Outline and updates their InheritedTags and Clocks.
+func (d *Document) addSections(nodes []Node) []Node {
+	for i, n := range nodes {
+		if h, ok := n.(Headline); ok {
+			h.InheritedTags = d.inheritedTags(h.Lvl)
+			d.addHeadline(&h)
+			h.Children = d.addSections(h.Children)
+			h.Clocks = collectClocks(h.Children)
+			nodes[i] = h
+		}
+	}
+	return nodes
+}
+
+// adoptHeadline prepares h (which might originate from another document) for being added to the document:
+// Levels are shifted so that h has level lvl, spans are removed and headlines get new indices.
+func (d *Document) adoptHeadline(h Headline, lvl int) Headline {
+	index := 0
+	Inspect(func(n Node) bool {
+		if h, ok := n.(Headline); ok && h.Index > index {
+			index = h.Index
+		}
+		return true
+	}, d.Nodes...)
+	nodes := withoutSpans([]Node{h.shiftLevel(lvl - h.Lvl)})
+	return Transform(nodes, func(n Node) []Node {
+		if h, ok := n.(Headline); ok {
+			index++
+			h.Index = index
+			return []Node{h}
+		}
+		return []Node{n}
+	})[0].(Headline)
+}
+
+// addLogEntry adds the list item entry to the LOGBOOK drawer of the headline. The drawer is created if necessary.
+func (h Headline) addLogEntry(d *Document, entry string) Headline {
+	logbook := withoutSpans(d.Parse(strings.NewReader(":LOGBOOK:\n"+entry+"\n:END:\n"), d.Path).Nodes)[0].(Drawer)
+	children := append([]Node{}, h.Children...)
+	for i, n := range children {
+		if drawer, ok := n.(Drawer); ok && drawer.Name == "LOGBOOK" {
+			if list, ok := firstNode(drawer.Children).(List); ok {
+				list.Items = append(logbook.Children[0].(List).Items, list.Items...)
+				drawer.Children = append([]Node{list}, drawer.Children[1:]...)
+			} else {
+				drawer.Children = append(logbook.Children, drawer.Children...)
+			}
+			children[i], h.Children = drawer, children
+			return h
+		}
+	}
+	h.Children = append([]Node{logbook}, children...)
+	return h
+}
+
+func firstNode(nodes []Node) Node {
+	if len(nodes) == 0 {
+		return nil
+	}
+	return nodes[0]
+}
+
+func (h Headline) shiftLevel(delta int) Headline {
+	h.Lvl += delta
+	children := make([]Node, len(h.Children))
+	for i, n := range h.Children {
+		if child, ok := n.(Headline); ok {
+			n = child.shiftLevel(delta)
+		}
+		children[i] = n
+	}
+	h.Children = children
+	return h
+}
+
+// withoutSpans returns a copy of nodes without spans - e.g. for nodes that are moved between documents.
+func withoutSpans(nodes []Node) []Node {
+	return Transform(nodes, func(n Node) []Node { return []Node{setSpan(n, Span{})} })
+}
+
+// flattenHeadlines returns nodes with all sub headlines moved out of their parent headlines, in document order.
+func flattenHeadlines(nodes []Node) []Node {
+	flat := []Node{}
+	for _, n := range nodes {
+		h, ok := n.(Headline)
+		if !ok {
+			flat = append(flat, n)
+			continue
+		}
+		children, headlines := []Node{}, []Node{}
+		for _, c := range h.Children {
+			if _, ok := c.(Headline); ok {
+				headlines = append(headlines, c)
+			} else {
+				children = append(children, c)
+			}
+		}
+		h.Children = children
+		flat = append(append(flat, h), flattenHeadlines(headlines)...)
+	}
+	return flat
+}
+
+// nestHeadlines is the inverse of flattenHeadlines: Nodes following a headline become its children
+// up to the next headline of the same or a lower level.
+func nestHeadlines(nodes []Node) []Node {
+	nested := []Node{}
+	for i := 0; i < len(nodes); i++ {
+		h, ok := nodes[i].(Headline)
+		if !ok {
+			nested = append(nested, nodes[i])
+			continue
+		}
+		j := i + 1
+		for ; j < len(nodes); j++ {
+			if next, ok := nodes[j].(Headline); ok && next.Lvl <= h.Lvl {
+				break
+			}
+		}
+		h.Children = append(append([]Node{}, h.Children...), nestHeadlines(nodes[i+1:j])...)
+		nested, i = append(nested, h), j-1
+	}
+	return nested
+}
@@ -0,0 +1,94 @@
+package org
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var editTestInput = `#+TODO: TODO | DONE
+* TODO  task  :work:
+:PROPERTIES:
+:ID:       task
+:EFFORT:   1:00
+:END:
+some   text
+* project
+** first
+** second
+*** third
+`
+
+func TestEditHeadlines(t *testing.T) {
+	c := New().Silent()
+	c.Lossless = true
+	d := c.Parse(strings.NewReader(editTestInput), "")
+	byTitle := func(title string) func(Headline) bool {
+		return func(h Headline) bool { return strings.TrimSpace(String(h.Title...)) == title }
+	}
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	if !d.EditHeadline(byTitle("task"), func(h Headline) Headline {
+		if _, err := h.SetStatus(d, "FINISHED", StatusChange{}); err == nil {
+			t.Error("expected an error for an unknown TODO keyword")
+		}
+		h, err := h.SetStatus(d, "DONE", StatusChange{Time: now, Closed: true, Log: true, Note: "finally"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h.SetProperty("effort", "2:00").DeleteProperty("ID").AddTag("done").RemoveTag("work")
+	}) {
+		t.Fatal("task not found")
+	}
+	if !d.MoveHeadlineUp(byTitle("second")) || d.MoveHeadlineUp(byTitle("second")) {
+		t.Error("expected second to be moved up exactly once")
+	}
+	d.EditHeadline(byTitle("third"), Headline.Promote)
+	d.InsertHeadline(byTitle("project"), Headline{Lvl: 5, Title: []Node{Text{Content: "inserted"}}})
+	if d.EditHeadline(byTitle("missing"), Headline.Demote) {
+		t.Error("expected no headline to match")
+	}
+	actual, _ := d.Write(NewOrgWriter())
+	expected := `#+TODO: TODO | DONE
+* DONE  task                                                           :done:
+CLOSED: [2024-01-01 Mon 10:00]
+:PROPERTIES:
+:EFFORT: 2:00
+:END:
+:LOGBOOK:
+- State "DONE"       from "TODO"       [2024-01-01 Mon 10:00] \\
+  finally
+:END:
+some   text
+* project
+** second
+** third
+** first
+** inserted
+`
+	if actual != expected {
+		t.Errorf("\n%s", diff(actual, expected))
+	}
+	if sections := d.Outline.Children[1].Children; len(sections) != 4 || sections[1].Headline.Lvl != 2 {
+		t.Errorf("outline was not updated: %d sections", len(sections))
+	}
+}
+
+func TestRefile(t *testing.T) {
+	source := New().Silent().Parse(strings.NewReader("* inbox\n** note\ntext\n*** detail\n"), "")
+	target := New().Silent().Parse(strings.NewReader("* archive\n** 2024\n"), "")
+	isTitle := func(title string) func(Headline) bool {
+		return func(h Headline) bool { return strings.TrimSpace(String(h.Title...)) == title }
+	}
+	if target.Refile(isTitle("archive"), source, isTitle("missing")) {
+		t.Error("refile to missing parent should fail")
+	}
+	if !source.Refile(isTitle("note"), target, isTitle("2024")) {
+		t.Fatal("refile failed")
+	}
+	if actual, expected := String(source.Nodes...), "* inbox\n"; actual != expected {
+		t.Errorf("source:\n%s", diff(actual, expected))
+	}
+	if actual, expected := String(target.Nodes...), "* archive\n** 2024\n*** note\ntext\n**** detail\n"; actual != expected {
+		t.Errorf("target:\n%s", diff(actual, expected))
+	}
+}
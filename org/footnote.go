@@ -8,6 +8,7 @@ type FootnoteDefinition struct {
 	Name     string
 	Children []Node
 	Inline   bool
+	Span     Span
 }
 
 var footnoteDefinitionRegexp = regexp.MustCompile(`^\[fn:([\w-]+)\](\s+(.+)|\s*$)`)
@@ -20,15 +21,17 @@ func lexFootnoteDefinition(line string) (token, bool) {
 }
 
 func (d *Document) parseFootnoteDefinition(i int, parentStop stopFn) (int, Node) {
-	start, name := i, d.tokens[i].content
-	d.tokens[i] = tokenize(d.tokens[i].matches[2])
+	start, name, startOffset := i, d.tokens[i].content, d.tokenStart(i)
+	d.retokenize(i, d.tokens[i].matches[2], 0)
 	stop := func(d *Document, i int) bool {
 		return parentStop(d, i) ||
 			(isSecondBlankLine(d, i) && i > start+1) ||
 			d.tokens[i].kind == "headline" || d.tokens[i].kind == "footnoteDefinition"
 	}
 	consumed, nodes := d.parseMany(i, stop)
-	definition := FootnoteDefinition{name, nodes, false}
+	span := d.tokenSpan(start, start+consumed)
+	span.Start = d.Position(startOffset)
+	definition := FootnoteDefinition{name, nodes, false, span}
 	return consumed, definition
 }
 
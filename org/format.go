@@ -0,0 +1,116 @@
+package org
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var orderedListBulletRegexp = regexp.MustCompile(`^[0-9]+([.)])$`)
+
+// Format returns the document as canonical Org mode: The document is pretty printed by OrgWriter (which e.g. aligns
+// tables and tags and upper cases keywords) with unordered list bullets replaced by "-", ordered lists numbered
+// consecutively and runs of blank lines between paragraphs and headlines collapsed into a single blank line.
+// Formatting never changes the HTML the document is rendered to - changes that would (e.g. collapsing
+// the two blank lines that end a list) are not applied. An error is returned if the pretty printed document
+// does not render to the same HTML as the document.
+func (d *Document) Format() (string, error) {
+	if d.Error != nil {
+		return "", d.Error
+	}
+	html, err := d.withNodes(d.Nodes).Write(NewHTMLWriter())
+	if err != nil {
+		return "", err
+	}
+	for _, nodes := range [][]Node{normalizeNodes(d.Nodes), d.Nodes} {
+		out, err := d.withNodes(nodes).Write(NewOrgWriter())
+		if err != nil {
+			return "", err
+		}
+		c := *d.Configuration
+		c.Log, c.Lossless = log.New(ioutil.Discard, "", 0), false
+		if formattedHTML, err := c.Parse(strings.NewReader(out), d.Path).Write(NewHTMLWriter()); err == nil && formattedHTML == html {
+			return out, nil
+		}
+	}
+	return "", fmt.Errorf("could not format %s: formatting would change the rendered HTML", d.Path)
+}
+
+// withNodes returns a copy of the document with the given nodes that is not written as its source (see Lossless).
+func (d *Document) withNodes(nodes []Node) *Document {
+	document := *d
+	document.Nodes, document.sourceNodes, document.Diagnostics = nodes, nil, nil
+	return &document
+}
+
+func normalizeNodes(nodes []Node) []Node {
+	return collapseBlankLines(Transform(nodes, func(n Node) []Node {
+		switch n := n.(type) {
+		case Headline:
+			n.Children = collapseBlankLines(n.Children)
+			return []Node{n}
+		case List:
+			n.Items = normalizeBullets(n.Items)
+			return []Node{n}
+		}
+		return []Node{n}
+	}))
+}
+
+// collapseBlankLines removes the empty paragraphs (i.e. blank lines) of nodes that are followed by another blank line.
+func collapseBlankLines(nodes []Node) []Node {
+	collapsed := []Node{}
+	for i, n := range nodes {
+		if isBlankLine(n) && i+1 < len(nodes) && startsWithBlankLine(nodes[i+1]) {
+			continue
+		}
+		collapsed = append(collapsed, n)
+	}
+	return collapsed
+}
+
+func isBlankLine(n Node) bool {
+	p, ok := n.(Paragraph)
+	return ok && len(p.Children) == 0
+}
+
+func startsWithBlankLine(n Node) bool {
+	if p, ok := n.(Paragraph); ok && len(p.Children) != 0 {
+		_, ok := p.Children[0].(LineBreak)
+		return ok
+	}
+	return isBlankLine(n)
+}
+
+// normalizeBullets replaces the bullets of unordered list items with "-" and numbers ordered list items consecutively,
+// starting at 1 or the value of the item (e.g. [@3]). Alphabetical bullets are not changed.
+func normalizeBullets(items []Node) []Node {
+	number := 1
+	for i, n := range items {
+		switch item := n.(type) {
+		case ListItem:
+			if item.Value != "" {
+				number, _ = strconv.Atoi(item.Value)
+			}
+			item.Bullet = normalizeBullet(item.Bullet, number)
+			items[i] = item
+		case DescriptiveListItem:
+			item.Bullet = normalizeBullet(item.Bullet, number)
+			items[i] = item
+		}
+		number++
+	}
+	return items
+}
+
+func normalizeBullet(bullet string, number int) string {
+	if bullet == "+" || bullet == "*" {
+		return "-"
+	} else if m := orderedListBulletRegexp.FindStringSubmatch(bullet); m != nil {
+		return strconv.Itoa(number) + m[1]
+	}
+	return bullet
+}
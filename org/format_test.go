@@ -0,0 +1,70 @@
+package org
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	input := strings.Join([]string{
+		"#+title: Format",
+		"",
+		"",
+		"",
+		"* TODO headline :a:b:",
+		"text",
+		"   + one",
+		"   + two",
+		"     3) three",
+		"     7) four",
+		"",
+		"",
+		"| a | bb |",
+		"|-",
+		"| ccc | d |",
+		"",
+		"",
+		"",
+		"** sub",
+	}, "\n")
+	expected := strings.Join([]string{
+		"#+TITLE: Format",
+		"",
+		"* TODO headline                                                         :a:b:",
+		"text",
+		"- one",
+		"- two",
+		"  1) three",
+		"  2) four",
+		"",
+		"",
+		"| a   | bb |",
+		"|-----+----|",
+		"| ccc | d  |",
+		"",
+		"** sub",
+		"",
+	}, "\n")
+	actual, err := New().Silent().Parse(strings.NewReader(input), "./format.org").Format()
+	if err != nil {
+		t.Fatal(err)
+	} else if actual != expected {
+		t.Errorf("%s", diff(actual, expected))
+	}
+}
+
+func TestFormatIsIdempotent(t *testing.T) {
+	for _, path := range orgTestFiles() {
+		formatted, err := New().Silent().Parse(strings.NewReader(fileString(t, path)), path).Format()
+		if err != nil {
+			t.Errorf("%s: %s", path, err)
+			continue
+		}
+		reformatted, err := New().Silent().Parse(strings.NewReader(formatted), path).Format()
+		if err != nil {
+			t.Errorf("%s: %s", path, err)
+		} else if reformatted != formatted {
+			t.Errorf("%s: formatting is not idempotent:\n%s", path, diff(reformatted, formatted))
+		}
+	}
+}
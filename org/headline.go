@@ -9,8 +9,9 @@ import (
 
 type Outline struct {
 	*Section
-	last  *Section
-	count int
+	last     *Section
+	count    int
+	selected bool // selected is true if any headline has one of the #+SELECT_TAGS.
 }
 
 type Section struct {
@@ -20,19 +21,28 @@ type Section struct {
 }
 
 type Headline struct {
-	Index      int
-	Lvl        int
-	Status     string
-	IsComment  bool
-	Priority   string
-	Properties *PropertyDrawer
-	Title      []Node
-	Tags       []string
-	Children   []Node
+	Index         int
+	Lvl           int
+	Status        string
+	IsComment     bool
+	Priority      string
+	Properties    *PropertyDrawer
+	Title         []Node
+	Tags          []string
+	InheritedTags []string // InheritedTags contains the tags inherited from #+FILETAGS and ancestor headlines - see EffectiveTags.
+	Children      []Node
+	Scheduled     *Timestamp
+	Deadline      *Timestamp
+	Closed        *Timestamp
+	PlanningOrder []string // PlanningOrder contains the planning keywords in the order of the planning line - see Planning.
+	Clocks        []Clock  // Clocks contains the CLOCK lines of the headline (excluding those of sub headlines).
+	Span          Span
 }
 
 var headlineRegexp = regexp.MustCompile(`^([*]+)\s+(.*)`)
 var tagRegexp = regexp.MustCompile(`(.*?)\s+(:[\p{L}0-9_@#%:]+:\s*$)`)
+var planningRegexp = regexp.MustCompile(`^\s*((SCHEDULED|DEADLINE|CLOSED):\s*(<[^>]+>|\[[^\]]+\])\s*)+$`)
+var planningKeywordRegexp = regexp.MustCompile(`(SCHEDULED|DEADLINE|CLOSED):\s*(<[^>]+>|\[[^\]]+\])`)
 
 func lexHeadline(line string) (token, bool) {
 	if m := headlineRegexp.FindStringSubmatch(line); m != nil {
@@ -45,10 +55,7 @@ func (d *Document) parseHeadline(i int, parentStop stopFn) (int, Node) {
 	t, headline := d.tokens[i], Headline{}
 	headline.Lvl = len(t.matches[1])
 	text := t.content
-	todoKeywords := trimFastTags(
-		strings.FieldsFunc(d.Get("TODO"), func(r rune) bool { return unicode.IsSpace(r) || r == '|' }),
-	)
-	for _, k := range todoKeywords {
+	for _, k := range d.TodoKeywords().Keywords() {
 		if strings.HasPrefix(text, k) && len(text) > len(k) && unicode.IsSpace(rune(text[len(k)])) {
 			headline.Status = k
 			text = text[len(k)+1:]
@@ -56,48 +63,96 @@ func (d *Document) parseHeadline(i int, parentStop stopFn) (int, Node) {
 		}
 	}
 
-	if len(text) >= 4 && text[0:2] == "[#" && strings.Contains("ABC", text[2:3]) && text[3] == ']' {
-		headline.Priority = text[2:3]
-		text = strings.TrimSpace(text[4:])
+	if m := headlinePriorityRegexp.FindStringSubmatch(text); m != nil && d.Priorities().IsValid(m[1]) {
+		headline.Priority = m[1]
+		text = strings.TrimSpace(text[len(m[0]):])
 	}
 	if strings.HasPrefix(text, "COMMENT ") {
 		headline.IsComment = true
 		text = strings.TrimPrefix(text, "COMMENT ")
 	}
+	at := d.lineOffsets(i, text)
 	if m := tagRegexp.FindStringSubmatch(text); m != nil {
 		text = m[1]
 		headline.Tags = strings.FieldsFunc(m[2], func(r rune) bool { return r == ':' })
 	}
+	headline.InheritedTags = d.inheritedTags(headline.Lvl)
 	headline.Index = d.addHeadline(&headline)
-	headline.Title = d.parseInline(text)
+	headline.Title = d.parseInline(text, at)
 
 	stop := func(d *Document, i int) bool {
 		return parentStop(d, i) || d.tokens[i].kind == "headline" && len(d.tokens[i].matches[1]) <= headline.Lvl
 	}
-	consumed, nodes := d.parseMany(i+1, stop)
-	if len(nodes) > 0 {
-		if d, ok := nodes[0].(PropertyDrawer); ok {
-			headline.Properties = &d
-			nodes = nodes[1:]
+	start := i + 1
+	if !stop(d, start) && d.parsePlanning(start, &headline) {
+		start++
+	}
+	if !stop(d, start) && d.tokens[start].kind == "beginDrawer" && d.tokens[start].content == "PROPERTIES" {
+		if consumed, node := d.parsePropertyDrawer(start, stop); consumed != 0 {
+			drawer := node.(PropertyDrawer)
+			headline.Properties, start = &drawer, start+consumed
 		}
 	}
+	consumed, nodes := d.parseMany(start, stop)
+	consumed += start - (i + 1)
 	headline.Children = nodes
+	headline.Clocks = collectClocks(nodes)
+	headline.Span = d.tokenSpan(i, i+consumed+1)
 	return consumed + 1, headline
 }
 
-func trimFastTags(tags []string) []string {
-	trimmedTags := make([]string, len(tags))
-	for i, t := range tags {
-		lParen := strings.LastIndex(t, "(")
-		rParen := strings.LastIndex(t, ")")
-		end := len(t) - 1
-		if lParen == end-2 && rParen == end {
-			trimmedTags[i] = t[:end-2]
-		} else {
-			trimmedTags[i] = t
+// parsePlanning parses the planning line (SCHEDULED, DEADLINE, CLOSED) of a headline from token i.
+func (d *Document) parsePlanning(i int, headline *Headline) bool {
+	t := d.tokens[i]
+	if t.kind != "text" || !planningRegexp.MatchString(t.matches[0]) {
+		return false
+	}
+	planning, order, line := map[string]*Timestamp{}, []string{}, t.matches[0]
+	at := func(j int) int { return d.offsets[i] + j }
+	for _, m := range planningKeywordRegexp.FindAllStringSubmatchIndex(line, -1) {
+		keyword, raw := line[m[2]:m[3]], line[m[4]:m[5]]
+		consumed, node := d.parseTimestamp(line, m[4], at)
+		if consumed != len(raw) {
+			return false
+		}
+		timestamp := node.(Timestamp)
+		timestamp.Span = d.inlineSpan(at, m[4], m[5])
+		if planning[keyword] == nil {
+			order = append(order, keyword)
 		}
+		planning[keyword] = &timestamp
 	}
-	return trimmedTags
+	headline.Scheduled, headline.Deadline, headline.Closed = planning["SCHEDULED"], planning["DEADLINE"], planning["CLOSED"]
+	headline.PlanningOrder = order
+	return true
+}
+
+// PlanningEntry is a keyword (SCHEDULED, DEADLINE, CLOSED) and its timestamp on the planning line of a headline.
+type PlanningEntry struct {
+	Keyword   string
+	Timestamp *Timestamp
+}
+
+// Planning returns the planning timestamps of the headline in the order of PlanningOrder.
+// Timestamps missing from PlanningOrder (e.g. a CLOSED timestamp added by SetStatus) come first,
+// ordered CLOSED, DEADLINE, SCHEDULED.
+func (h Headline) Planning() []PlanningEntry {
+	timestamps := map[string]*Timestamp{"CLOSED": h.Closed, "DEADLINE": h.Deadline, "SCHEDULED": h.Scheduled}
+	entries, ordered := []PlanningEntry{}, map[string]bool{}
+	for _, keyword := range h.PlanningOrder {
+		ordered[keyword] = true
+	}
+	for _, keyword := range []string{"CLOSED", "DEADLINE", "SCHEDULED"} {
+		if t := timestamps[keyword]; t != nil && !ordered[keyword] {
+			entries = append(entries, PlanningEntry{keyword, t})
+		}
+	}
+	for _, keyword := range h.PlanningOrder {
+		if t := timestamps[keyword]; t != nil {
+			entries = append(entries, PlanningEntry{keyword, t})
+		}
+	}
+	return entries
 }
 
 func (h Headline) ID() string {
@@ -107,15 +162,34 @@ func (h Headline) ID() string {
 	return fmt.Sprintf("headline-%d", h.Index)
 }
 
+// IsExcluded returns true if the headline is not exported. That is the case for commented headlines, headlines with
+// one of the #+EXCLUDE_TAGS and - if any headline of the document has one of the #+SELECT_TAGS - for headlines
+// that are neither selected themselves nor ancestors of selected headlines.
 func (h Headline) IsExcluded(d *Document) bool {
-	if h.IsComment {
+	if h.IsComment || h.hasTag(d, "EXCLUDE_TAGS") {
+		return true
+	}
+	return d.Outline.selected && !h.isSelected(d)
+}
+
+func (h Headline) isSelected(d *Document) bool {
+	if h.hasTag(d, "SELECT_TAGS") {
 		return true
 	}
-	for _, excludedTag := range strings.Fields(d.Get("EXCLUDE_TAGS")) {
-		for _, tag := range h.Tags {
-			if tag == excludedTag {
-				return true
-			}
+	for _, n := range h.Children {
+		if child, ok := n.(Headline); ok && child.isSelected(d) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTag returns true if the headline has one of the tags of the setting key (including inherited and #+TAGS group tags).
+func (h Headline) hasTag(d *Document, key string) bool {
+	tags := d.TagDefinitions().Expand(h.EffectiveTags())
+	for _, tag := range strings.Fields(d.Get(key)) {
+		if containsString(tags, tag) {
+			return true
 		}
 	}
 	return false
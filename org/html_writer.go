@@ -3,7 +3,6 @@ package org
 import (
 	"fmt"
 	"html"
-	"log"
 	"regexp"
 	"strconv"
 	"strings"
@@ -35,17 +34,19 @@ type HTMLWriter struct {
 	// :html-toplevel-hlevel export property and the associated
 	// org-html-toplevel-hlevel variable.
 	TopLevelHLevel int
+	// Diagnostics contains the problems found while writing (e.g. bad macros). They are not added to Document.Diagnostics.
+	Diagnostics []Diagnostic
 
 	strings.Builder
 	document   *Document
 	htmlEscape bool
-	log        *log.Logger
 	footnotes  *footnotes
 }
 
 type footnotes struct {
 	mapping map[string]int
 	list    []*FootnoteDefinition
+	spans   []Span // spans[i] is the span of the first link to list[i].
 	unused  map[string]*FootnoteDefinition
 }
 
@@ -79,7 +80,6 @@ func NewHTMLWriter() *HTMLWriter {
 	defaultConfig := New()
 	return &HTMLWriter{
 		document:   &Document{Configuration: defaultConfig},
-		log:        defaultConfig.Log,
 		htmlEscape: true,
 		HighlightCodeBlock: func(source, lang string, inline bool, params map[string]string) string {
 			if inline {
@@ -113,7 +113,6 @@ func (w *HTMLWriter) WriterWithExtensions() Writer {
 
 func (w *HTMLWriter) Before(d *Document) {
 	w.document = d
-	w.log = d.Log
 	if title := d.Get("TITLE"); title != "" && w.document.GetOption("title") != "nil" {
 		titleDocument := d.Parse(strings.NewReader(title), d.Path)
 		if titleDocument.Error == nil {
@@ -208,6 +207,20 @@ func (w *HTMLWriter) WriteDrawer(d Drawer) {
 	WriteNodes(w, d.Children...)
 }
 
+func (w *HTMLWriter) WriteClock(c Clock) {
+	if w.document.GetOption("c") == "nil" {
+		return
+	}
+	w.WriteString(`<p><span class="timestamp-wrapper"><span class="timestamp-kwd">CLOCK:</span> `)
+	w.WriteString(w.timestamp(c.Timestamp))
+	if c.Timestamp.End != nil {
+		w.WriteString(fmt.Sprintf(` <span class="timestamp">(%s)</span>`, formatClockDuration(c.Duration)))
+	}
+	w.WriteString("</span></p>\n")
+}
+
+func (w *HTMLWriter) WriteLogEntry(e LogEntry) { WriteNodes(w, e.Item) }
+
 func (w *HTMLWriter) WriteKeyword(k Keyword) {
 	if k.Key == "HTML" {
 		w.WriteString(k.Value + "\n")
@@ -246,7 +259,8 @@ func (w *HTMLWriter) WriteFootnotes(d *Document) {
 					name = k
 				}
 			}
-			w.log.Printf("Missing footnote definition for [fn:%s] (#%d)", name, id)
+			w.Diagnostics = append(w.Diagnostics, w.document.diagnostic(SeverityWarning, CodeMissingFootnoteDefinition, w.footnotes.spans[i],
+				"Missing footnote definition for [fn:%s] (#%d)", name, id))
 			continue
 		}
 		w.WriteString(`<div class="footnote-definition">` + "\n")
@@ -293,6 +307,7 @@ func (w *HTMLWriter) writeSection(section *Section, maxLvl int) {
 
 func (w *HTMLWriter) WriteHeadline(h Headline) {
 	if h.IsExcluded(w.document) {
+		w.footnotes.collectDefinitions(h.Children)
 		return
 	}
 
@@ -301,7 +316,11 @@ func (w *HTMLWriter) WriteHeadline(h Headline) {
 	w.WriteString(fmt.Sprintf(`<div id="outline-container-%s" class="outline-%d">`, h.ID(), level) + "\n")
 	w.WriteString(fmt.Sprintf(`<h%d id="%s">`, level, h.ID()) + "\n")
 	if w.document.GetOption("todo") != "nil" && h.Status != "" {
-		w.WriteString(fmt.Sprintf(`<span class="todo status-%s">%s</span>`, strings.ToLower(h.Status), h.Status) + "\n")
+		class := "todo"
+		if w.document.TodoKeywords().IsDone(h.Status) {
+			class = "done"
+		}
+		w.WriteString(fmt.Sprintf(`<span class="%s status-%s">%s</span>`, class, strings.ToLower(h.Status), h.Status) + "\n")
 	}
 	if w.document.GetOption("pri") != "nil" && h.Priority != "" {
 		w.WriteString(fmt.Sprintf(`<span class="priority priority-%s">[%s]</span>`, strings.ToLower(h.Priority), h.Priority) + "\n")
@@ -317,12 +336,24 @@ func (w *HTMLWriter) WriteHeadline(h Headline) {
 		w.WriteString(fmt.Sprintf(`<span class="tags">%s</span>`, strings.Join(tags, "&#xa0;")))
 	}
 	w.WriteString(fmt.Sprintf("\n</h%d>\n", level))
-	if content := w.WriteNodesAsString(h.Children...); content != "" {
+	if content := w.planning(h) + w.WriteNodesAsString(h.Children...); content != "" {
 		w.WriteString(fmt.Sprintf(`<div id="outline-text-%s" class="outline-text-%d">`, h.ID(), level) + "\n" + content + "</div>\n")
 	}
 	w.WriteString("</div>\n")
 }
 
+func (w *HTMLWriter) planning(h Headline) string {
+	entries := h.Planning()
+	if w.document.GetOption("p") == "nil" || len(entries) == 0 {
+		return ""
+	}
+	planning := make([]string, len(entries))
+	for i, p := range entries {
+		planning[i] = fmt.Sprintf(`<span class="timestamp-kwd">%s:</span> %s`, p.Keyword, w.timestamp(*p.Timestamp))
+	}
+	return fmt.Sprintf(`<p><span class="timestamp-wrapper">%s</span></p>`+"\n", strings.Join(planning, " "))
+}
+
 func (w *HTMLWriter) WriteText(t Text) {
 	if !w.htmlEscape {
 		w.WriteString(t.Content)
@@ -376,24 +407,37 @@ func (w *HTMLWriter) WriteTimestamp(t Timestamp) {
 	if w.document.GetOption("<") == "nil" {
 		return
 	}
-	w.WriteString(`<span class="timestamp">&lt;`)
-	if t.IsDate {
-		w.WriteString(t.Time.Format(datestampFormat))
-	} else {
-		w.WriteString(t.Time.Format(timestampFormat))
-	}
-	if t.Interval != "" {
-		w.WriteString(" " + t.Interval)
-	}
-	w.WriteString(`&gt;</span>`)
+	w.WriteString(w.timestamp(t))
+}
+
+// timestamp returns the html of t. Unlike WriteTimestamp it ignores the < option, as planning and
+// clock timestamps are not affected by it.
+func (w *HTMLWriter) timestamp(t Timestamp) string {
+	return `<span class="timestamp">` + html.EscapeString(String(t)) + `</span>`
 }
 
 func (w *HTMLWriter) WriteRegularLink(l RegularLink) {
+	if id, title, ok := w.document.ResolveInternalLink(l); ok {
+		description := l.Description
+		if description == nil {
+			description = title
+		}
+		w.WriteString(fmt.Sprintf(`<a href="#%s">%s</a>`, html.EscapeString(id), w.WriteNodesAsString(description...)))
+		return
+	}
 	url := html.EscapeString(l.URL)
 	if l.Protocol == "file" {
 		url = url[len("file:"):]
 	}
-	if isRelative := l.Protocol == "file" || l.Protocol == ""; isRelative && w.PrettyRelativeLinks {
+	anchor, isRelative := "", l.Protocol == "file" || l.Protocol == ""
+	if i := strings.Index(url, "::"); isRelative && i != -1 {
+		// search options like ::#custom-id (see Workspace) become anchors, other search options are not supported
+		if strings.HasPrefix(url[i+2:], "#") {
+			anchor = url[i+2:]
+		}
+		url = url[:i]
+	}
+	if isRelative && w.PrettyRelativeLinks {
 		if !strings.HasPrefix(url, "/") {
 			url = "../" + url
 		}
@@ -403,6 +447,7 @@ func (w *HTMLWriter) WriteRegularLink(l RegularLink) {
 	} else if isRelative && strings.HasSuffix(url, ".org") {
 		url = strings.TrimSuffix(url, ".org") + ".html"
 	}
+	url += anchor
 	if prefix := w.document.Links[l.Protocol]; prefix != "" {
 		if tag := strings.TrimPrefix(l.URL, l.Protocol+":"); strings.Contains(prefix, "%s") || strings.Contains(prefix, "%h") {
 			url = html.EscapeString(strings.ReplaceAll(strings.ReplaceAll(prefix, "%s", tag), "%h", u.QueryEscape(tag)))
@@ -436,6 +481,14 @@ func (w *HTMLWriter) WriteRegularLink(l RegularLink) {
 	}
 }
 
+func (w *HTMLWriter) WriteTarget(t Target) {
+	w.WriteString(fmt.Sprintf(`<a id="%s"></a>`, html.EscapeString(targetID(t.Name))))
+}
+
+func (w *HTMLWriter) WriteRadioTarget(t RadioTarget) {
+	w.WriteString(fmt.Sprintf(`<a id="%s"></a>%s`, html.EscapeString(targetID(t.Name)), html.EscapeString(t.Name)))
+}
+
 func (w *HTMLWriter) WriteMacro(m Macro) {
 	if macro := w.document.Macros[m.Name]; macro != "" {
 		for i, param := range m.Parameters {
@@ -443,7 +496,7 @@ func (w *HTMLWriter) WriteMacro(m Macro) {
 		}
 		macroDocument := w.document.Parse(strings.NewReader(macro), w.document.Path)
 		if macroDocument.Error != nil {
-			w.log.Printf("bad macro: %s -> %s: %v", m.Name, macro, macroDocument.Error)
+			w.Diagnostics = append(w.Diagnostics, w.document.diagnostic(SeverityWarning, CodeBadMacro, m.Span, "bad macro: %s -> %s: %v", m.Name, macro, macroDocument.Error))
 		}
 		WriteNodes(w, macroDocument.Nodes...)
 	}
@@ -553,6 +606,7 @@ func (w *HTMLWriter) WriteNodeWithMeta(n NodeWithMeta) {
 }
 
 func (w *HTMLWriter) WriteNodeWithName(n NodeWithName) {
+	w.WriteString(fmt.Sprintf(`<a id="%s"></a>`, html.EscapeString(targetID(n.Name))) + "\n")
 	WriteNodes(w, n.Node)
 }
 
@@ -603,13 +657,13 @@ func (w *HTMLWriter) writeTableColumns(columns []Column, tag string) {
 
 func (w *HTMLWriter) withHTMLAttributes(input string, kvs ...string) string {
 	if len(kvs)%2 != 0 {
-		w.log.Printf("withHTMLAttributes: Len of kvs must be even: %#v", kvs)
+		w.Diagnostics = append(w.Diagnostics, w.document.diagnostic(SeverityWarning, CodeBadHTMLAttributes, Span{}, "withHTMLAttributes: Len of kvs must be even: %#v", kvs))
 		return input
 	}
 	context := &h.Node{Type: h.ElementNode, Data: "body", DataAtom: atom.Body}
 	nodes, err := h.ParseFragment(strings.NewReader(strings.TrimSpace(input)), context)
 	if err != nil || len(nodes) != 1 {
-		w.log.Printf("withHTMLAttributes: Could not extend attributes of %s: %v (%s)", input, nodes, err)
+		w.Diagnostics = append(w.Diagnostics, w.document.diagnostic(SeverityWarning, CodeBadHTMLAttributes, Span{}, "withHTMLAttributes: Could not extend attributes of %s: %v (%s)", input, nodes, err))
 		return input
 	}
 	out, node := strings.Builder{}, nodes[0]
@@ -618,7 +672,7 @@ func (w *HTMLWriter) withHTMLAttributes(input string, kvs ...string) string {
 	}
 	err = h.Render(&out, nodes[0])
 	if err != nil {
-		w.log.Printf("withHTMLAttributes: Could not extend attributes of %s: %v (%s)", input, node, err)
+		w.Diagnostics = append(w.Diagnostics, w.document.diagnostic(SeverityWarning, CodeBadHTMLAttributes, Span{}, "withHTMLAttributes: Could not extend attributes of %s: %v (%s)", input, node, err))
 		return input
 	}
 	return out.String()
@@ -674,7 +728,7 @@ func (fs *footnotes) add(f FootnoteLink) int {
 		delete(fs.unused, f.Name)
 	}
 
-	fs.list = append(fs.list, f.Definition)
+	fs.list, fs.spans = append(fs.list, f.Definition), append(fs.spans, f.Span)
 	i := len(fs.list) - 1
 	if f.Name != "" {
 		fs.mapping[f.Name] = i
@@ -682,6 +736,19 @@ func (fs *footnotes) add(f FootnoteLink) int {
 	return i
 }
 
+// collectDefinitions registers the footnote definitions of excluded headlines without writing them.
+// That way footnotes referenced from exported content can be defined in e.g. an unselected Footnotes headline.
+func (fs *footnotes) collectDefinitions(nodes []Node) {
+	for _, n := range nodes {
+		switch n := n.(type) {
+		case FootnoteDefinition:
+			fs.updateDefinition(n)
+		case Headline:
+			fs.collectDefinitions(n.Children)
+		}
+	}
+}
+
 func (fs *footnotes) updateDefinition(f FootnoteDefinition) {
 	if i, ok := fs.mapping[f.Name]; ok {
 		fs.list[i] = &f
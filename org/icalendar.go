@@ -0,0 +1,126 @@
+package org
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var icalendarNow = time.Now
+
+var icalendarFrequencies = map[string]string{"h": "HOURLY", "d": "DAILY", "w": "WEEKLY", "m": "MONTHLY", "y": "YEARLY"}
+
+var icalendarTextReplacer = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+// ICalendar exports the document as an iCalendar (RFC 5545).
+// Headlines with a status that is not done become VTODOs (SCHEDULED is their start, DEADLINE their due date).
+// SCHEDULED and DEADLINE timestamps of other headlines and active timestamps in headline titles and sections become VEVENTs.
+// UIDs are based on :ID: properties - headlines without one get a UID derived from the document path and their position.
+func (d *Document) ICalendar() string {
+	b := &strings.Builder{}
+	writeICalendarLines(b, "BEGIN:VCALENDAR", "VERSION:2.0", "PRODID:-//go-org//go-org//EN", "CALSCALE:GREGORIAN")
+	if title := d.Get("TITLE"); title != "" {
+		writeICalendarLines(b, "X-WR-CALNAME:"+icalendarTextReplacer.Replace(title))
+	}
+	dtstamp := "DTSTAMP:" + icalendarNow().UTC().Format("20060102T150405Z")
+	d.walkAgendaHeadlines(d.Nodes, AgendaFilter{}, func(h Headline, tags []string) {
+		if h.IsExcluded(d) {
+			return
+		}
+		id, ok := h.Properties.Get("ID")
+		if !ok {
+			id = fmt.Sprintf("%x", sha1.Sum([]byte(fmt.Sprintf("%s:%d:%s", d.Path, h.Index, String(h.Title...)))))
+		}
+		summary := "SUMMARY:" + icalendarTextReplacer.Replace(strings.TrimSpace(String(h.Title...)))
+		categories := []string{}
+		if len(tags) != 0 {
+			escaped := make([]string, len(tags))
+			for i, tag := range tags {
+				escaped[i] = icalendarTextReplacer.Replace(tag)
+			}
+			categories = append(categories, "CATEGORIES:"+strings.Join(escaped, ","))
+		}
+		event := func(uid string, t Timestamp) {
+			writeICalendarLines(b, "BEGIN:VEVENT", "UID:"+uid, dtstamp)
+			writeICalendarLines(b, icalendarRange(t)...)
+			writeICalendarLines(b, summary)
+			writeICalendarLines(b, categories...)
+			writeICalendarLines(b, "END:VEVENT")
+		}
+		if h.Status != "" && !d.TodoKeywords().IsDone(h.Status) {
+			writeICalendarLines(b, "BEGIN:VTODO", "UID:TODO-"+id, dtstamp)
+			if h.Scheduled != nil {
+				writeICalendarLines(b, icalendarTime("DTSTART", *h.Scheduled, h.Scheduled.Time))
+				writeICalendarLines(b, icalendarRRule(*h.Scheduled)...)
+			}
+			if h.Deadline != nil {
+				writeICalendarLines(b, icalendarTime("DUE", *h.Deadline, h.Deadline.Time))
+			}
+			writeICalendarLines(b, summary)
+			writeICalendarLines(b, categories...)
+			writeICalendarLines(b, "STATUS:NEEDS-ACTION", "END:VTODO")
+		} else if h.Status == "" {
+			if h.Scheduled != nil {
+				event("SC-"+id, *h.Scheduled)
+			}
+			if h.Deadline != nil {
+				event("DL-"+id, *h.Deadline)
+			}
+		}
+		n := 0
+		for _, t := range collectTimestamps(append(append([]Node{}, h.Title...), h.Children...)) {
+			if !t.IsInactive && t.Diary == "" {
+				n++
+				event(fmt.Sprintf("TS%d-%s", n, id), t)
+			}
+		}
+	})
+	writeICalendarLines(b, "END:VCALENDAR")
+	return b.String()
+}
+
+// writeICalendarLines writes lines as CRLF terminated content lines folded at 75 octets.
+func writeICalendarLines(b *strings.Builder, lines ...string) {
+	for _, line := range lines {
+		for n := 75; len(line) > n; n = 74 {
+			i := n
+			for i > 0 && line[i]&0xC0 == 0x80 {
+				i--
+			}
+			b.WriteString(line[:i] + "\r\n ")
+			line = line[i:]
+		}
+		b.WriteString(line + "\r\n")
+	}
+}
+
+func icalendarRange(t Timestamp) []string {
+	lines := []string{icalendarTime("DTSTART", t, t.Time)}
+	switch {
+	case t.End != nil:
+		end := t.End.Time
+		if t.IsDate {
+			end = end.AddDate(0, 0, 1)
+		}
+		lines = append(lines, icalendarTime("DTEND", t, end))
+	case t.IsDate:
+		lines = append(lines, icalendarTime("DTEND", t, t.Time.AddDate(0, 0, 1)))
+	}
+	return append(lines, icalendarRRule(t)...)
+}
+
+func icalendarTime(property string, t Timestamp, tt time.Time) string {
+	if t.IsDate {
+		return property + ";VALUE=DATE:" + tt.Format("20060102")
+	}
+	return property + ":" + tt.Format("20060102T150405")
+}
+
+func icalendarRRule(t Timestamp) []string {
+	_, value, unit := t.Repeater()
+	if value == 0 {
+		return nil
+	}
+	return []string{fmt.Sprintf("RRULE:FREQ=%s;INTERVAL=%d", icalendarFrequencies[unit], value)}
+}
@@ -0,0 +1,76 @@
+package org
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestICalendar(t *testing.T) {
+	icalendarNow = func() time.Time { return time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC) }
+	defer func() { icalendarNow = time.Now }()
+	d := New().Silent().Parse(strings.NewReader(`#+TITLE: Team, calendar
+* TODO Write report :work:urgent:
+SCHEDULED: <2024-01-01 Mon 10:00 +1w> DEADLINE: <2024-01-05 Fri>
+:PROPERTIES:
+:ID: report
+:END:
+* Meeting
+:PROPERTIES:
+:ID: meeting
+:END:
+<2024-01-02 Tue 14:00-15:30 +2w> and <2024-01-04 Thu>--<2024-01-05 Fri>
+* DONE Done :noexport:
+SCHEDULED: <2024-01-02 Tue>
+`), "")
+	expected := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//go-org//go-org//EN",
+		"CALSCALE:GREGORIAN",
+		`X-WR-CALNAME:Team\, calendar`,
+		"BEGIN:VTODO",
+		"UID:TODO-report",
+		"DTSTAMP:20240101T120000Z",
+		"DTSTART:20240101T100000",
+		"RRULE:FREQ=WEEKLY;INTERVAL=1",
+		"DUE;VALUE=DATE:20240105",
+		"SUMMARY:Write report",
+		"CATEGORIES:work,urgent",
+		"STATUS:NEEDS-ACTION",
+		"END:VTODO",
+		"BEGIN:VEVENT",
+		"UID:TS1-meeting",
+		"DTSTAMP:20240101T120000Z",
+		"DTSTART:20240102T140000",
+		"DTEND:20240102T153000",
+		"RRULE:FREQ=WEEKLY;INTERVAL=2",
+		"SUMMARY:Meeting",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:TS2-meeting",
+		"DTSTAMP:20240101T120000Z",
+		"DTSTART;VALUE=DATE:20240104",
+		"DTEND;VALUE=DATE:20240106",
+		"SUMMARY:Meeting",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+	if actual := d.ICalendar(); actual != expected {
+		t.Errorf("unexpected ics:\n%s", diff(actual, expected))
+	}
+}
+
+func TestICalendarLineFolding(t *testing.T) {
+	b := &strings.Builder{}
+	writeICalendarLines(b, "SUMMARY:"+strings.Repeat("ä", 40))
+	for _, line := range strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n") {
+		if len(line) > 75 || !strings.HasPrefix(line, "SUMMARY:") && !strings.HasPrefix(line, " ") {
+			t.Errorf("bad folded line: %q", line)
+		}
+	}
+	if unfolded := strings.ReplaceAll(b.String(), "\r\n ", ""); unfolded != "SUMMARY:"+strings.Repeat("ä", 40)+"\r\n" {
+		t.Errorf("bad unfolded line: %q", unfolded)
+	}
+}
@@ -1,11 +1,9 @@
 package org
 
 import (
-	"fmt"
 	"path"
 	"regexp"
 	"strings"
-	"time"
 	"unicode"
 	"unicode/utf8"
 )
@@ -13,42 +11,45 @@ import (
 type Text struct {
 	Content string
 	IsRaw   bool
+	Span    Span
 }
 
 type LineBreak struct {
 	Count                      int
 	BetweenMultibyteCharacters bool
+	Span                       Span
 }
-type ExplicitLineBreak struct{}
+type ExplicitLineBreak struct{ Span Span }
 
-type StatisticToken struct{ Content string }
-
-type Timestamp struct {
-	Time     time.Time
-	IsDate   bool
-	Interval string
+type StatisticToken struct {
+	Content string
+	Span    Span
 }
 
 type Emphasis struct {
 	Kind    string
 	Content []Node
+	Span    Span
 }
 
 type InlineBlock struct {
 	Name       string
 	Parameters []string
 	Children   []Node
+	Span       Span
 }
 
 type LatexFragment struct {
 	OpeningPair string
 	ClosingPair string
 	Content     []Node
+	Span        Span
 }
 
 type FootnoteLink struct {
 	Name       string
 	Definition *FootnoteDefinition
+	Span       Span
 }
 
 type RegularLink struct {
@@ -56,11 +57,13 @@ type RegularLink struct {
 	Description []Node
 	URL         string
 	AutoLink    bool
+	Span        Span
 }
 
 type Macro struct {
 	Name       string
 	Parameters []string
+	Span       Span
 }
 
 var validURLCharacters = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-._~:/?#[]@!$&'()*+,;="
@@ -69,7 +72,6 @@ var imageExtensionRegexp = regexp.MustCompile(`(?i)^[.](png|gif|jpe?g|svg|tiff?|
 var videoExtensionRegexp = regexp.MustCompile(`(?i)^[.](webm|mp4)$`)
 
 var subScriptSuperScriptRegexp = regexp.MustCompile(`^([_^]){([^{}]+?)}`)
-var timestampRegexp = regexp.MustCompile(`^<(\d{4}-\d{2}-\d{2})( [A-Za-z]+)?( \d{2}:\d{2})?( \+\d+[dwmy])?>`)
 var footnoteRegexp = regexp.MustCompile(`^\[fn:([\w-]*?)(:(.*?))?\]`)
 var statisticsTokenRegexp = regexp.MustCompile(`^\[(\d+/\d+|\d+%)\]`)
 var latexFragmentRegexp = regexp.MustCompile(`(?s)^\\begin{(\w+)}(.*)\\end{(\w+)}`)
@@ -77,9 +79,6 @@ var inlineBlockRegexp = regexp.MustCompile(`src_(\w+)(\[([^\]]*)\])?{([^}]*)}`)
 var inlineExportBlockRegexp = regexp.MustCompile(`@@(\w+):(.*?)@@`)
 var macroRegexp = regexp.MustCompile(`{{{(.*)\((.*)\)}}}`)
 
-var timestampFormat = "2006-01-02 Mon 15:04"
-var datestampFormat = "2006-01-02 Mon"
-
 var latexFragmentPairs = map[string]string{
 	`\(`: `\)`,
 	`\[`: `\]`,
@@ -87,43 +86,48 @@ var latexFragmentPairs = map[string]string{
 	`$`:  `$`,
 }
 
-func (d *Document) parseInline(input string) (nodes []Node) {
+func (d *Document) parseInline(input string, at offsetFn) (nodes []Node) {
 	previous, current := 0, 0
 	for current < len(input) {
 		rewind, consumed, node := 0, 0, (Node)(nil)
 		switch input[current] {
 		case '^':
-			consumed, node = d.parseSubOrSuperScript(input, current)
+			consumed, node = d.parseSubOrSuperScript(input, current, at)
 		case '_':
-			rewind, consumed, node = d.parseSubScriptOrEmphasisOrInlineBlock(input, current)
+			rewind, consumed, node = d.parseSubScriptOrEmphasisOrInlineBlock(input, current, at)
 		case '@':
-			consumed, node = d.parseInlineExportBlock(input, current)
+			consumed, node = d.parseInlineExportBlock(input, current, at)
 		case '*', '/', '+':
-			consumed, node = d.parseEmphasis(input, current, false)
+			consumed, node = d.parseEmphasis(input, current, false, at)
 		case '=', '~':
-			consumed, node = d.parseEmphasis(input, current, true)
+			consumed, node = d.parseEmphasis(input, current, true, at)
 		case '[':
-			consumed, node = d.parseOpeningBracket(input, current)
+			consumed, node = d.parseOpeningBracket(input, current, at)
 		case '{':
 			consumed, node = d.parseMacro(input, current)
 		case '<':
-			consumed, node = d.parseTimestamp(input, current)
+			if consumed, node = d.parseTarget(input, current); consumed == 0 {
+				consumed, node = d.parseTimestamp(input, current, at)
+			}
 		case '\\':
-			consumed, node = d.parseExplicitLineBreakOrLatexFragment(input, current)
+			consumed, node = d.parseExplicitLineBreakOrLatexFragment(input, current, at)
 		case '$':
-			consumed, node = d.parseLatexFragment(input, current, 1)
+			consumed, node = d.parseLatexFragment(input, current, 1, at)
 		case '\n':
 			consumed, node = d.parseLineBreak(input, current)
 		case ':':
 			rewind, consumed, node = d.parseAutoLink(input, current)
 		}
+		if consumed == 0 && len(d.radioTargets) != 0 {
+			consumed, node = d.parseRadioLink(input, current, at)
+		}
 		current -= rewind
 		if consumed != 0 {
 			if current > previous {
-				nodes = append(nodes, Text{input[previous:current], false})
+				nodes = append(nodes, Text{input[previous:current], false, d.inlineSpan(at, previous, current)})
 			}
 			if node != nil {
-				nodes = append(nodes, node)
+				nodes = append(nodes, setSpan(node, d.inlineSpan(at, current, current+consumed)))
 			}
 			current += consumed
 			previous = current
@@ -133,20 +137,20 @@ func (d *Document) parseInline(input string) (nodes []Node) {
 	}
 
 	if previous < len(input) {
-		nodes = append(nodes, Text{input[previous:], false})
+		nodes = append(nodes, Text{input[previous:], false, d.inlineSpan(at, previous, len(input))})
 	}
 	return nodes
 }
 
-func (d *Document) parseRawInline(input string) (nodes []Node) {
+func (d *Document) parseRawInline(input string, at offsetFn) (nodes []Node) {
 	previous, current := 0, 0
 	for current < len(input) {
 		if input[current] == '\n' {
 			consumed, node := d.parseLineBreak(input, current)
 			if current > previous {
-				nodes = append(nodes, Text{input[previous:current], true})
+				nodes = append(nodes, Text{input[previous:current], true, d.inlineSpan(at, previous, current)})
 			}
-			nodes = append(nodes, node)
+			nodes = append(nodes, setSpan(node, d.inlineSpan(at, current, current+consumed)))
 			current += consumed
 			previous = current
 		} else {
@@ -154,7 +158,7 @@ func (d *Document) parseRawInline(input string) (nodes []Node) {
 		}
 	}
 	if previous < len(input) {
-		nodes = append(nodes, Text{input[previous:], true})
+		nodes = append(nodes, Text{input[previous:], true, d.inlineSpan(at, previous, len(input))})
 	}
 	return nodes
 }
@@ -165,27 +169,30 @@ func (d *Document) parseLineBreak(input string, start int) (int, Node) {
 	}
 	_, beforeLen := utf8.DecodeLastRuneInString(input[:start])
 	_, afterLen := utf8.DecodeRuneInString(input[i:])
-	return i - start, LineBreak{i - start, beforeLen > 1 && afterLen > 1}
+	return i - start, LineBreak{i - start, beforeLen > 1 && afterLen > 1, Span{}}
 }
 
-func (d *Document) parseInlineBlock(input string, start int) (int, int, Node) {
+func (d *Document) parseInlineBlock(input string, start int, at offsetFn) (int, int, Node) {
 	if !(strings.HasSuffix(input[:start], "src") && (start-4 < 0 || unicode.IsSpace(rune(input[start-4])))) {
 		return 0, 0, nil
 	}
-	if m := inlineBlockRegexp.FindStringSubmatch(input[start-3:]); m != nil {
-		return 3, len(m[0]), InlineBlock{"src", strings.Fields(m[1] + " " + m[3]), d.parseRawInline(m[4])}
+	if m := inlineBlockRegexp.FindStringSubmatchIndex(input[start-3:]); m != nil {
+		s := func(i int) string { return submatch(input[start-3:], m, i) }
+		content := d.parseRawInline(s(4), at.shift(start-3+m[8]))
+		return 3, m[1], InlineBlock{"src", strings.Fields(s(1) + " " + s(3)), content, Span{}}
 	}
 	return 0, 0, nil
 }
 
-func (d *Document) parseInlineExportBlock(input string, start int) (int, Node) {
-	if m := inlineExportBlockRegexp.FindStringSubmatch(input[start:]); m != nil {
-		return len(m[0]), InlineBlock{"export", m[1:2], d.parseRawInline(m[2])}
+func (d *Document) parseInlineExportBlock(input string, start int, at offsetFn) (int, Node) {
+	if m := inlineExportBlockRegexp.FindStringSubmatchIndex(input[start:]); m != nil {
+		content := d.parseRawInline(input[start+m[4]:start+m[5]], at.shift(start+m[4]))
+		return m[1], InlineBlock{"export", []string{input[start+m[2] : start+m[3]]}, content, Span{}}
 	}
 	return 0, nil
 }
 
-func (d *Document) parseExplicitLineBreakOrLatexFragment(input string, start int) (int, Node) {
+func (d *Document) parseExplicitLineBreakOrLatexFragment(input string, start int, at offsetFn) (int, Node) {
 	switch {
 	case start+2 >= len(input):
 	case input[start+1] == '\\' && start != 0 && input[start-1] != '\n':
@@ -195,20 +202,21 @@ func (d *Document) parseExplicitLineBreakOrLatexFragment(input string, start int
 			}
 		}
 	case input[start+1] == '(' || input[start+1] == '[':
-		return d.parseLatexFragment(input, start, 2)
+		return d.parseLatexFragment(input, start, 2, at)
 	case strings.Index(input[start:], `\begin{`) == 0:
 		if m := latexFragmentRegexp.FindStringSubmatch(input[start:]); m != nil {
 			if open, content, close := m[1], m[2], m[3]; open == close {
 				openingPair, closingPair := `\begin{`+open+`}`, `\end{`+close+`}`
 				i := strings.Index(input[start:], closingPair)
-				return i + len(closingPair), LatexFragment{openingPair, closingPair, d.parseRawInline(content)}
+				content := d.parseRawInline(content, at.shift(start+len(openingPair)))
+				return i + len(closingPair), LatexFragment{openingPair, closingPair, content, Span{}}
 			}
 		}
 	}
 	return 0, nil
 }
 
-func (d *Document) parseLatexFragment(input string, start int, pairLength int) (int, Node) {
+func (d *Document) parseLatexFragment(input string, start int, pairLength int, at offsetFn) (int, Node) {
 	if start+2 >= len(input) {
 		return 0, nil
 	}
@@ -218,34 +226,37 @@ func (d *Document) parseLatexFragment(input string, start int, pairLength int) (
 	openingPair := input[start : start+pairLength]
 	closingPair := latexFragmentPairs[openingPair]
 	if i := strings.Index(input[start+pairLength:], closingPair); i != -1 {
-		content := d.parseRawInline(input[start+pairLength : start+pairLength+i])
-		return i + pairLength + pairLength, LatexFragment{openingPair, closingPair, content}
+		content := d.parseRawInline(input[start+pairLength:start+pairLength+i], at.shift(start+pairLength))
+		return i + pairLength + pairLength, LatexFragment{openingPair, closingPair, content, Span{}}
 	}
 	return 0, nil
 }
 
-func (d *Document) parseSubOrSuperScript(input string, start int) (int, Node) {
+func (d *Document) parseSubOrSuperScript(input string, start int, at offsetFn) (int, Node) {
 	if m := subScriptSuperScriptRegexp.FindStringSubmatch(input[start:]); m != nil {
-		return len(m[2]) + 3, Emphasis{m[1] + "{}", []Node{Text{m[2], false}}}
+		text := Text{m[2], false, d.inlineSpan(at, start+2, start+2+len(m[2]))}
+		return len(m[2]) + 3, Emphasis{m[1] + "{}", []Node{text}, Span{}}
 	}
 	return 0, nil
 }
 
-func (d *Document) parseSubScriptOrEmphasisOrInlineBlock(input string, start int) (int, int, Node) {
-	if rewind, consumed, node := d.parseInlineBlock(input, start); consumed != 0 {
+func (d *Document) parseSubScriptOrEmphasisOrInlineBlock(input string, start int, at offsetFn) (int, int, Node) {
+	if rewind, consumed, node := d.parseInlineBlock(input, start, at); consumed != 0 {
 		return rewind, consumed, node
-	} else if consumed, node := d.parseSubOrSuperScript(input, start); consumed != 0 {
+	} else if consumed, node := d.parseSubOrSuperScript(input, start, at); consumed != 0 {
 		return 0, consumed, node
 	}
-	consumed, node := d.parseEmphasis(input, start, false)
+	consumed, node := d.parseEmphasis(input, start, false, at)
 	return 0, consumed, node
 }
 
-func (d *Document) parseOpeningBracket(input string, start int) (int, Node) {
+func (d *Document) parseOpeningBracket(input string, start int, at offsetFn) (int, Node) {
 	if len(input[start:]) >= 2 && input[start] == '[' && input[start+1] == '[' {
-		return d.parseRegularLink(input, start)
+		return d.parseRegularLink(input, start, at)
+	} else if timestampRegexp.MatchString(input[start:]) {
+		return d.parseTimestamp(input, start, at)
 	} else if footnoteRegexp.MatchString(input[start:]) {
-		return d.parseFootnoteReference(input, start)
+		return d.parseFootnoteReference(input, start, at)
 	} else if statisticsTokenRegexp.MatchString(input[start:]) {
 		return d.parseStatisticToken(input, start)
 	}
@@ -254,29 +265,31 @@ func (d *Document) parseOpeningBracket(input string, start int) (int, Node) {
 
 func (d *Document) parseMacro(input string, start int) (int, Node) {
 	if m := macroRegexp.FindStringSubmatch(input[start:]); m != nil {
-		return len(m[0]), Macro{m[1], strings.Split(m[2], ",")}
+		return len(m[0]), Macro{m[1], strings.Split(m[2], ","), Span{}}
 	}
 	return 0, nil
 }
 
-func (d *Document) parseFootnoteReference(input string, start int) (int, Node) {
-	if m := footnoteRegexp.FindStringSubmatch(input[start:]); m != nil {
-		name, definition := m[1], m[3]
+func (d *Document) parseFootnoteReference(input string, start int, at offsetFn) (int, Node) {
+	if m := footnoteRegexp.FindStringSubmatchIndex(input[start:]); m != nil {
+		name, definition := submatch(input[start:], m, 1), submatch(input[start:], m, 3)
 		if name == "" && definition == "" {
 			return 0, nil
 		}
-		link := FootnoteLink{name, nil}
+		link := FootnoteLink{name, nil, Span{}}
 		if definition != "" {
-			link.Definition = &FootnoteDefinition{name, []Node{Paragraph{d.parseInline(definition)}}, true}
+			at, span := at.shift(start+m[6]), d.inlineSpan(at, start, start+m[1])
+			paragraph := Paragraph{d.parseInline(definition, at), d.inlineSpan(at, 0, len(definition))}
+			link.Definition = &FootnoteDefinition{name, []Node{paragraph}, true, span}
 		}
-		return len(m[0]), link
+		return m[1], link
 	}
 	return 0, nil
 }
 
 func (d *Document) parseStatisticToken(input string, start int) (int, Node) {
 	if m := statisticsTokenRegexp.FindStringSubmatch(input[start:]); m != nil {
-		return len(m[1]) + 2, StatisticToken{m[1]}
+		return len(m[1]) + 2, StatisticToken{m[1], Span{}}
 	}
 	return 0, nil
 }
@@ -304,10 +317,10 @@ func (d *Document) parseAutoLink(input string, start int) (int, int, Node) {
 	if path == "://" {
 		return 0, 0, nil
 	}
-	return len(protocol), len(path + protocol), RegularLink{protocol, nil, protocol + path, true}
+	return len(protocol), len(path + protocol), RegularLink{protocol, nil, protocol + path, true, Span{}}
 }
 
-func (d *Document) parseRegularLink(input string, start int) (int, Node) {
+func (d *Document) parseRegularLink(input string, start int, at offsetFn) (int, Node) {
 	input = input[start:]
 	if len(input) < 3 || input[:2] != "[[" || input[2] == '[' {
 		return 0, nil
@@ -319,7 +332,10 @@ func (d *Document) parseRegularLink(input string, start int) (int, Node) {
 	rawLinkParts := strings.Split(input[2:end], "][")
 	description, link := ([]Node)(nil), rawLinkParts[0]
 	if len(rawLinkParts) == 2 {
-		link, description = rawLinkParts[0], d.parseInline(rawLinkParts[1])
+		radioTargets := d.radioTargets
+		d.radioTargets = nil
+		link, description = rawLinkParts[0], d.parseInline(rawLinkParts[1], at.shift(start+2+len(rawLinkParts[0])+2))
+		d.radioTargets = radioTargets
 	}
 	if strings.ContainsRune(link, '\n') {
 		return 0, nil
@@ -332,23 +348,7 @@ func (d *Document) parseRegularLink(input string, start int) (int, Node) {
 	return consumed, d.ResolveLink(protocol, description, link)
 }
 
-func (d *Document) parseTimestamp(input string, start int) (int, Node) {
-	if m := timestampRegexp.FindStringSubmatch(input[start:]); m != nil {
-		ddmmyy, hhmm, interval, isDate := m[1], m[3], strings.TrimSpace(m[4]), false
-		if hhmm == "" {
-			hhmm, isDate = "00:00", true
-		}
-		t, err := time.Parse(timestampFormat, fmt.Sprintf("%s Mon %s", ddmmyy, hhmm))
-		if err != nil {
-			return 0, nil
-		}
-		timestamp := Timestamp{t, isDate, interval}
-		return len(m[0]), timestamp
-	}
-	return 0, nil
-}
-
-func (d *Document) parseEmphasis(input string, start int, isRaw bool) (int, Node) {
+func (d *Document) parseEmphasis(input string, start int, isRaw bool, at offsetFn) (int, Node) {
 	marker, i := input[start], start
 	if !hasValidPreAndBorderChars(input, i) {
 		return 0, nil
@@ -360,9 +360,9 @@ func (d *Document) parseEmphasis(input string, start int, isRaw bool) (int, Node
 
 		if input[i] == marker && i != start+1 && hasValidPostAndBorderChars(input, i) {
 			if isRaw {
-				return i + 1 - start, Emphasis{input[start : start+1], d.parseRawInline(input[start+1 : i])}
+				return i + 1 - start, Emphasis{input[start : start+1], d.parseRawInline(input[start+1:i], at.shift(start+1)), Span{}}
 			}
-			return i + 1 - start, Emphasis{input[start : start+1], d.parseInline(input[start+1 : i])}
+			return i + 1 - start, Emphasis{input[start : start+1], d.parseInline(input[start+1:i], at.shift(start+1)), Span{}}
 		}
 	}
 	return 0, nil
@@ -399,6 +399,13 @@ func isValidPostChar(r rune) bool {
 
 func isValidBorderChar(r rune) bool { return !unicode.IsSpace(r) }
 
+func submatch(input string, m []int, i int) string {
+	if m[2*i] == -1 {
+		return ""
+	}
+	return input[m[2*i]:m[2*i+1]]
+}
+
 func (l RegularLink) Kind() string {
 	description := String(l.Description...)
 	descProtocol, descExt := strings.SplitN(description, ":", 2)[0], path.Ext(description)
@@ -430,4 +437,3 @@ func (n LatexFragment) String() string     { return String(n) }
 func (n FootnoteLink) String() string      { return String(n) }
 func (n RegularLink) String() string       { return String(n) }
 func (n Macro) String() string             { return String(n) }
-func (n Timestamp) String() string         { return String(n) }
@@ -0,0 +1,272 @@
+package org
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// jsonNodeTypes contains the node types that can be decoded by UnmarshalJSON - indexed by their Type discriminator.
+var jsonNodeTypes = map[string]reflect.Type{}
+
+var nodeType = reflect.TypeOf((*Node)(nil)).Elem()
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+var jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+func init() {
+	for _, n := range []Node{
+		Keyword{}, Include{}, Comment{}, NodeWithMeta{}, NodeWithName{}, Headline{}, Block{}, Result{}, LatexBlock{},
+		InlineBlock{}, Example{}, Drawer{}, PropertyDrawer{}, List{}, ListItem{}, DescriptiveListItem{}, Table{},
+		HorizontalRule{}, Paragraph{}, Text{}, Emphasis{}, LatexFragment{}, StatisticToken{}, ExplicitLineBreak{},
+		LineBreak{}, RegularLink{}, Target{}, RadioTarget{}, Macro{}, Timestamp{}, FootnoteLink{}, FootnoteDefinition{},
+		Clock{}, LogEntry{},
+	} {
+		jsonNodeTypes[reflect.TypeOf(n).Name()] = reflect.TypeOf(n)
+	}
+}
+
+type jsonDocument struct {
+	Path           string
+	Nodes          json.RawMessage
+	Outline        []jsonSection
+	BufferSettings map[string]string
+	NamedNodes     map[string]json.RawMessage
+	Links          map[string]string
+	Macros         map[string]string
+}
+
+// jsonSection is the JSON form of a Section. The headline of a section is referenced by its ID rather than
+// duplicated, the Outline is rebuilt from the headlines in Nodes when decoding.
+type jsonSection struct {
+	ID       string
+	Lvl      int
+	Title    json.RawMessage
+	Children []jsonSection
+}
+
+// MarshalJSON returns the JSON form of nodes: an array of objects with a Type discriminator (e.g. "Headline")
+// and the exported fields of the node. Nested nodes are encoded the same way.
+// The node returned by Include.Resolve is encoded as its Resolve field.
+func MarshalJSON(nodes ...Node) ([]byte, error) {
+	if nodes == nil {
+		nodes = []Node{}
+	}
+	return json.Marshal(toJSONValue(reflect.ValueOf(nodes)))
+}
+
+// UnmarshalJSON decodes nodes from their JSON form (see MarshalJSON).
+func UnmarshalJSON(data []byte) ([]Node, error) {
+	nodes := []Node{}
+	v, err := fromJSONValue(data, reflect.TypeOf(nodes))
+	if err != nil {
+		return nil, err
+	}
+	return v.Interface().([]Node), nil
+}
+
+// MarshalJSON returns the JSON form of the document: Path, Nodes (see MarshalJSON), Outline, BufferSettings,
+// NamedNodes, Links and Macros. The Outline is encoded as a table of contents, i.e. without section contents.
+func (d *Document) MarshalJSON() ([]byte, error) {
+	nodes, err := MarshalJSON(d.Nodes...)
+	if err != nil {
+		return nil, err
+	}
+	namedNodes := map[string]json.RawMessage{}
+	for name, n := range d.NamedNodes {
+		if namedNodes[name], err = json.Marshal(toJSONValue(reflect.ValueOf(&n).Elem())); err != nil {
+			return nil, err
+		}
+	}
+	outline, err := marshalSections(d.Outline.Children)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonDocument{d.Path, nodes, outline, d.BufferSettings, namedNodes, d.Links, d.Macros})
+}
+
+// UnmarshalJSON decodes the document from its JSON form (see MarshalJSON). The Outline is rebuilt from the
+// decoded headlines. A document without Configuration gets the default Configuration (see New).
+func (d *Document) UnmarshalJSON(data []byte) error {
+	document := jsonDocument{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return err
+	}
+	nodes, err := UnmarshalJSON(document.Nodes)
+	if err != nil {
+		return err
+	}
+	namedNodes := map[string]Node{}
+	for name, raw := range document.NamedNodes {
+		v, err := fromJSONValue(raw, nodeType)
+		if err != nil {
+			return err
+		}
+		namedNodes[name], _ = v.Interface().(Node)
+	}
+	if d.Configuration == nil {
+		d.Configuration = New()
+	}
+	d.Path, d.NamedNodes = document.Path, namedNodes
+	d.BufferSettings, d.Links, d.Macros = document.BufferSettings, document.Links, document.Macros
+	if d.BufferSettings == nil {
+		d.BufferSettings = map[string]string{}
+	}
+	d.updateNodes(nodes)
+	return nil
+}
+
+func marshalSections(sections []*Section) ([]jsonSection, error) {
+	jsonSections := []jsonSection{}
+	for _, s := range sections {
+		title, err := MarshalJSON(s.Headline.Title...)
+		if err != nil {
+			return nil, err
+		}
+		children, err := marshalSections(s.Children)
+		if err != nil {
+			return nil, err
+		}
+		jsonSections = append(jsonSections, jsonSection{s.Headline.ID(), s.Headline.Lvl, title, children})
+	}
+	return jsonSections, nil
+}
+
+// toJSONValue converts v into a value that encodes nodes (as described in MarshalJSON) when passed to json.Marshal.
+func toJSONValue(v reflect.Value) interface{} {
+	if v.Type().Implements(jsonMarshalerType) {
+		return v.Interface()
+	}
+	switch v.Kind() {
+	case reflect.Interface, reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		return toJSONValue(v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		values := make([]interface{}, v.Len())
+		for i := range values {
+			values[i] = toJSONValue(v.Index(i))
+		}
+		return values
+	case reflect.Map:
+		values := map[string]interface{}{}
+		for _, k := range v.MapKeys() {
+			values[fmt.Sprint(k.Interface())] = toJSONValue(v.MapIndex(k))
+		}
+		return values
+	case reflect.Struct:
+		values := map[string]interface{}{}
+		if jsonNodeTypes[v.Type().Name()] == v.Type() {
+			values["Type"] = v.Type().Name()
+		}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.PkgPath != "" {
+				continue
+			} else if field.Type.Kind() == reflect.Func {
+				if include, ok := v.Interface().(Include); ok && field.Name == "Resolve" && include.Resolve != nil {
+					resolved := include.Resolve()
+					values[field.Name] = toJSONValue(reflect.ValueOf(&resolved).Elem())
+				}
+				continue
+			}
+			values[field.Name] = toJSONValue(v.Field(i))
+		}
+		return values
+	}
+	return v.Interface()
+}
+
+// fromJSONValue decodes data into a new value of type t - the inverse of toJSONValue.
+func fromJSONValue(data json.RawMessage, t reflect.Type) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	if len(data) == 0 || string(data) == "null" {
+		return v, nil
+	}
+	if t == nodeType {
+		object := struct{ Type string }{}
+		if err := json.Unmarshal(data, &object); err != nil {
+			return v, err
+		}
+		nt, ok := jsonNodeTypes[object.Type]
+		if !ok {
+			return v, fmt.Errorf("unknown node type %q", object.Type)
+		}
+		n, err := fromJSONValue(data, nt)
+		if err != nil {
+			return v, err
+		}
+		v.Set(n)
+		return v, nil
+	}
+	if reflect.PtrTo(t).Implements(jsonUnmarshalerType) {
+		return v, json.Unmarshal(data, v.Addr().Interface())
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		elem, err := fromJSONValue(data, t.Elem())
+		if err != nil {
+			return v, err
+		}
+		v.Set(reflect.New(t.Elem()))
+		v.Elem().Set(elem)
+	case reflect.Slice:
+		raws := []json.RawMessage{}
+		if err := json.Unmarshal(data, &raws); err != nil {
+			return v, err
+		}
+		v.Set(reflect.MakeSlice(t, len(raws), len(raws)))
+		for i, raw := range raws {
+			elem, err := fromJSONValue(raw, t.Elem())
+			if err != nil {
+				return v, err
+			}
+			v.Index(i).Set(elem)
+		}
+	case reflect.Map:
+		raws := map[string]json.RawMessage{}
+		if err := json.Unmarshal(data, &raws); err != nil {
+			return v, err
+		}
+		v.Set(reflect.MakeMap(t))
+		for k, raw := range raws {
+			elem, err := fromJSONValue(raw, t.Elem())
+			if err != nil {
+				return v, err
+			}
+			v.SetMapIndex(reflect.ValueOf(k).Convert(t.Key()), elem)
+		}
+	case reflect.Struct:
+		raws := map[string]json.RawMessage{}
+		if err := json.Unmarshal(data, &raws); err != nil {
+			return v, err
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.PkgPath != "" {
+				continue
+			} else if field.Type.Kind() == reflect.Func {
+				if t == reflect.TypeOf(Include{}) && field.Name == "Resolve" {
+					resolved, err := fromJSONValue(raws[field.Name], nodeType)
+					if err != nil {
+						return v, err
+					}
+					n, _ := resolved.Interface().(Node)
+					v.Field(i).Set(reflect.ValueOf(func() Node { return n }))
+				}
+				continue
+			}
+			elem, err := fromJSONValue(raws[field.Name], field.Type)
+			if err != nil {
+				return v, fmt.Errorf("%s.%s: %s", t.Name(), field.Name, err)
+			}
+			v.Field(i).Set(elem)
+		}
+	default:
+		return v, json.Unmarshal(data, v.Addr().Interface())
+	}
+	return v, nil
+}
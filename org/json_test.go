@@ -0,0 +1,46 @@
+package org
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	for _, path := range orgTestFiles() {
+		d := New().Silent().Parse(strings.NewReader(fileString(t, path)), path)
+		bs, err := json.Marshal(d)
+		if err != nil {
+			t.Fatalf("%s: %s", path, err)
+		}
+		decoded := &Document{Configuration: d.Configuration}
+		if err := json.Unmarshal(bs, decoded); err != nil {
+			t.Fatalf("%s: %s", path, err)
+		}
+		for _, newWriter := range []func() Writer{func() Writer { return NewOrgWriter() }, func() Writer { return NewHTMLWriter() }} {
+			expected, _ := d.Write(newWriter())
+			if actual, err := decoded.Write(newWriter()); err != nil || actual != expected {
+				t.Errorf("%s: %v\n%s", path, err, diff(actual, expected))
+			}
+		}
+		if len(decoded.Outline.Children) != len(d.Outline.Children) || decoded.Outline.count != d.Outline.count {
+			t.Errorf("%s: bad outline", path)
+		}
+	}
+}
+
+func TestMarshalJSON(t *testing.T) {
+	d := New().Silent().Parse(strings.NewReader("* headline :tag:\n[[https://example.com][link]]\n"), "")
+	bs, err := MarshalJSON(d.Nodes...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{`"Type":"Headline"`, `"Tags":["tag"]`, `"Type":"RegularLink"`, `"URL":"https://example.com"`} {
+		if !strings.Contains(string(bs), s) {
+			t.Errorf("%s does not contain %s", bs, s)
+		}
+	}
+	if _, err := UnmarshalJSON([]byte(`[{"Type":"Unknown"}]`)); err == nil {
+		t.Errorf("expected error for unknown node type")
+	}
+}
@@ -7,26 +7,33 @@ import (
 	"strings"
 )
 
-type Comment struct{ Content string }
+type Comment struct {
+	Content string
+	Span    Span
+}
 
 type Keyword struct {
 	Key   string
 	Value string
+	Span  Span
 }
 
 type NodeWithName struct {
 	Name string
 	Node Node
+	Span Span
 }
 
 type NodeWithMeta struct {
 	Node Node
 	Meta Metadata
+	Span Span
 }
 
 type Metadata struct {
-	Caption        [][]Node
-	HTMLAttributes [][]string
+	Caption         [][]Node
+	HTMLAttributes  [][]string
+	LaTeXAttributes [][]string
 }
 
 type Include struct {
@@ -37,6 +44,14 @@ type Include struct {
 var keywordRegexp = regexp.MustCompile(`^(\s*)#\+([^:]+):(\s+(.*)|$)`)
 var commentRegexp = regexp.MustCompile(`^(\s*)#\s(.*)`)
 
+// fileSettings contains the keys of settings that apply to the whole document - no matter where their keyword is.
+// They are used while parsing (e.g. #+FILETAGS for the InheritedTags of headlines) and thus collected beforehand -
+// see collectFileSettings.
+var fileSettings = map[string]bool{
+	"FILETAGS": true, "TAGS": true, "SELECT_TAGS": true, "EXCLUDE_TAGS": true, "PRIORITIES": true, "PROPERTY": true,
+	"TODO": true, "SEQ_TODO": true, "TYP_TODO": true,
+}
+
 var includeFileRegexp = regexp.MustCompile(`(?i)^"([^"]+)" (src|example|export) (\w+)$`)
 var attributeRegexp = regexp.MustCompile(`(?:^|\s+)(:[-\w]+)\s+(.*)$`)
 
@@ -50,11 +65,12 @@ func lexKeywordOrComment(line string) (token, bool) {
 }
 
 func (d *Document) parseComment(i int, stop stopFn) (int, Node) {
-	return 1, Comment{d.tokens[i].content}
+	return 1, Comment{d.tokens[i].content, d.tokenSpan(i, i+1)}
 }
 
 func (d *Document) parseKeyword(i int, stop stopFn) (int, Node) {
 	k := parseKeyword(d.tokens[i])
+	k.Span = d.tokenSpan(i, i+1)
 	switch k.Key {
 	case "NAME":
 		return d.parseNodeWithName(k, i, stop)
@@ -72,22 +88,52 @@ func (d *Document) parseKeyword(i int, stop stopFn) (int, Node) {
 			d.Macros[parts[0]] = parts[1]
 		}
 		return 1, k
-	case "CAPTION", "ATTR_HTML":
+	case "CAPTION", "ATTR_HTML", "ATTR_LATEX":
 		consumed, node := d.parseAffiliated(i, stop)
 		if consumed != 0 {
 			return consumed, node
 		}
 		fallthrough
 	default:
-		if _, ok := d.BufferSettings[k.Key]; ok {
-			d.BufferSettings[k.Key] = strings.Join([]string{d.BufferSettings[k.Key], k.Value}, "\n")
-		} else {
-			d.BufferSettings[k.Key] = k.Value
+		if !fileSettings[k.Key] {
+			d.addSetting(k.Key, k.Value)
 		}
 		return 1, k
 	}
 }
 
+// addSetting adds the value of a keyword to the BufferSettings. Values of repeated keywords are joined by newlines.
+func (d *Document) addSetting(key, value string) {
+	if _, ok := d.BufferSettings[key]; ok {
+		d.BufferSettings[key] = strings.Join([]string{d.BufferSettings[key], value}, "\n")
+	} else {
+		d.BufferSettings[key] = value
+	}
+}
+
+// collectFileSettings adds the fileSettings keywords of the document to the BufferSettings before parsing.
+// Keywords in raw text blocks (e.g. SRC and EXAMPLE blocks) are ignored.
+func (d *Document) collectFileSettings() {
+	for i := 0; i < len(d.tokens); i++ {
+		switch t := d.tokens[i]; t.kind {
+		case "beginBlock":
+			if !isRawTextBlock(t.content) {
+				break
+			}
+			for j := i + 1; j < len(d.tokens); j++ {
+				if d.tokens[j].kind == "endBlock" && d.tokens[j].content == t.content {
+					i = j
+					break
+				}
+			}
+		case "keyword":
+			if k := parseKeyword(t); fileSettings[k.Key] {
+				d.addSetting(k.Key, k.Value)
+			}
+		}
+	}
+}
+
 func (d *Document) parseNodeWithName(k Keyword, i int, stop stopFn) (int, Node) {
 	if stop(d, i+1) {
 		return 0, nil
@@ -97,7 +143,7 @@ func (d *Document) parseNodeWithName(k Keyword, i int, stop stopFn) (int, Node)
 		return 0, nil
 	}
 	d.NamedNodes[k.Value] = node
-	return consumed + 1, NodeWithName{k.Value, node}
+	return consumed + 1, NodeWithName{k.Value, node, d.tokenSpan(i, i+consumed+1)}
 }
 
 func (d *Document) parseAffiliated(i int, stop stopFn) (int, Node) {
@@ -105,25 +151,11 @@ func (d *Document) parseAffiliated(i int, stop stopFn) (int, Node) {
 	for ; !stop(d, i) && d.tokens[i].kind == "keyword"; i++ {
 		switch k := parseKeyword(d.tokens[i]); k.Key {
 		case "CAPTION":
-			meta.Caption = append(meta.Caption, d.parseInline(k.Value))
+			meta.Caption = append(meta.Caption, d.parseInline(k.Value, d.lineOffsets(i, k.Value)))
 		case "ATTR_HTML":
-			attributes, rest := []string{}, k.Value
-			for {
-				if k, m := "", attributeRegexp.FindStringSubmatch(rest); m != nil {
-					k, rest = m[1], m[2]
-					attributes = append(attributes, k)
-					if v, m := "", attributeRegexp.FindStringSubmatchIndex(rest); m != nil {
-						v, rest = rest[:m[0]], rest[m[0]:]
-						attributes = append(attributes, v)
-					} else {
-						attributes = append(attributes, strings.TrimSpace(rest))
-						break
-					}
-				} else {
-					break
-				}
-			}
-			meta.HTMLAttributes = append(meta.HTMLAttributes, attributes)
+			meta.HTMLAttributes = append(meta.HTMLAttributes, parseAttributes(k.Value))
+		case "ATTR_LATEX":
+			meta.LaTeXAttributes = append(meta.LaTeXAttributes, parseAttributes(k.Value))
 		default:
 			return 0, nil
 		}
@@ -136,34 +168,53 @@ func (d *Document) parseAffiliated(i int, stop stopFn) (int, Node) {
 		return 0, nil
 	}
 	i += consumed
-	return i - start, NodeWithMeta{node, meta}
+	return i - start, NodeWithMeta{node, meta, d.tokenSpan(start, i)}
+}
+
+// parseAttributes parses the :key value pairs of #+ATTR_ keywords into a flat list of keys and values.
+func parseAttributes(value string) []string {
+	attributes, rest := []string{}, value
+	for {
+		if k, m := "", attributeRegexp.FindStringSubmatch(rest); m != nil {
+			k, rest = m[1], m[2]
+			attributes = append(attributes, k)
+			if v, m := "", attributeRegexp.FindStringSubmatchIndex(rest); m != nil {
+				v, rest = rest[:m[0]], rest[m[0]:]
+				attributes = append(attributes, v)
+			} else {
+				attributes = append(attributes, strings.TrimSpace(rest))
+				break
+			}
+		} else {
+			break
+		}
+	}
+	return attributes
 }
 
 func parseKeyword(t token) Keyword {
 	k, v := t.matches[2], t.matches[4]
-	return Keyword{strings.ToUpper(k), strings.TrimSpace(v)}
+	return Keyword{strings.ToUpper(k), strings.TrimSpace(v), Span{}}
 }
 
 func (d *Document) parseInclude(k Keyword) (int, Node) {
-	resolve := func() Node {
-		d.Log.Printf("Bad include %#v", k)
-		return k
-	}
-	if m := includeFileRegexp.FindStringSubmatch(k.Value); m != nil {
-		path, kind, lang := m[1], m[2], m[3]
-		if !filepath.IsAbs(path) {
-			path = filepath.Join(filepath.Dir(d.Path), path)
-		}
-		resolve = func() Node {
-			bs, err := d.ReadFile(path)
-			if err != nil {
-				d.Log.Printf("Bad include %#v: %s", k, err)
-				return k
-			}
-			return Block{strings.ToUpper(kind), []string{lang}, d.parseRawInline(string(bs)), nil}
-		}
+	m := includeFileRegexp.FindStringSubmatch(k.Value)
+	if m == nil {
+		d.addDiagnostic(SeverityWarning, CodeBadInclude, k.Span, "Bad include %#v", k)
+		return 1, Include{k, func() Node { return k }}
 	}
-	return 1, Include{k, resolve}
+	path, kind, lang := m[1], m[2], m[3]
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(d.Path), path)
+	}
+	// the file is read while parsing so that problems are reported as diagnostics of the document
+	bs, err := d.ReadFile(path)
+	if err != nil {
+		d.addDiagnostic(SeverityError, CodeBadInclude, k.Span, "Bad include %#v: %s", k, err)
+		return 1, Include{k, func() Node { return k }}
+	}
+	block := Block{strings.ToUpper(kind), []string{lang}, d.parseRawInline(string(bs), nil), nil, nil, Span{}}
+	return 1, Include{k, func() Node { return block }}
 }
 
 func (d *Document) loadSetupFile(k Keyword) (int, Node) {
@@ -173,16 +224,20 @@ func (d *Document) loadSetupFile(k Keyword) (int, Node) {
 	}
 	bs, err := d.ReadFile(path)
 	if err != nil {
-		d.Log.Printf("Bad setup file: %#v: %s", k, err)
+		d.addDiagnostic(SeverityError, CodeBadSetupFile, k.Span, "Bad setup file: %#v: %s", k, err)
 		return 1, k
 	}
 	setupDocument := d.Configuration.Parse(bytes.NewReader(bs), path)
 	if err := setupDocument.Error; err != nil {
-		d.Log.Printf("Bad setup file: %#v: %s", k, err)
+		d.addDiagnostic(SeverityError, CodeBadSetupFile, k.Span, "Bad setup file: %#v: %s", k, err)
 		return 1, k
 	}
 	for k, v := range setupDocument.BufferSettings {
-		d.BufferSettings[k] = v
+		if fileSettings[k] {
+			d.addSetting(k, v)
+		} else {
+			d.BufferSettings[k] = v
+		}
 	}
 	return 1, k
 }
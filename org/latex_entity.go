@@ -0,0 +1,439 @@
+package org
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// latexReplacer escapes the special characters of LaTeX and replaces org entities with their LaTeX equivalents.
+// latexEscapeReplacer only escapes the special characters.
+var latexReplacer, latexEscapeReplacer *strings.Replacer
+
+var latexSpecialCharacters = []string{
+	`\`, `\textbackslash{}`,
+	`{`, `\{`,
+	`}`, `\}`,
+	`$`, `\$`,
+	`&`, `\&`,
+	`#`, `\#`,
+	`%`, `\%`,
+	`_`, `\_`,
+	`^`, `\textasciicircum{}`,
+	`~`, `\textasciitilde{}`,
+}
+
+func init() {
+	for i := 1; i <= 20; i++ {
+		latexEntities = append(latexEntities, []string{`\_` + strings.Repeat(" ", i), fmt.Sprintf(`\hspace*{%.1fem}`, float64(i)/2)})
+	}
+	latexEntities = append(latexEntities, []string{"...", `\ldots{}`})
+	// replace longer entities first to prevent partial replacements (e.g. \angle as \ang + le)
+	sort.SliceStable(latexEntities, func(i, j int) bool {
+		return len(latexEntities[i][0]) > len(latexEntities[j][0])
+	})
+	xs := []string{}
+	for _, kv := range latexEntities {
+		// replace both \<entity> and \<entity>{}
+		xs = append(xs, kv[0]+"{}", kv[1], kv[0], kv[1])
+	}
+	// the entities take precedence over the special characters as the replacer tries the pairs in order
+	latexReplacer = strings.NewReplacer(append(xs, latexSpecialCharacters...)...)
+	latexEscapeReplacer = strings.NewReplacer(latexSpecialCharacters...)
+}
+
+// latexEntities maps org entities to the LaTeX representation of org-entities. Entities that are only
+// available in math mode are wrapped in \( \).
+var latexEntities = [][]string{
+	{`\Agrave`, "\\`{A}"},
+	{`\agrave`, "\\`{a}"},
+	{`\Aacute`, `\'{A}`},
+	{`\aacute`, `\'{a}`},
+	{`\Acirc`, `\^{A}`},
+	{`\acirc`, `\^{a}`},
+	{`\Amacr`, `\={A}`},
+	{`\amacr`, `\={a}`},
+	{`\Atilde`, `\~{A}`},
+	{`\atilde`, `\~{a}`},
+	{`\Auml`, `\"{A}`},
+	{`\auml`, `\"{a}`},
+	{`\Aring`, `\AA{}`},
+	{`\AA`, `\AA{}`},
+	{`\aring`, `\aa{}`},
+	{`\AElig`, `\AE{}`},
+	{`\aelig`, `\ae{}`},
+	{`\Ccedil`, `\c{C}`},
+	{`\ccedil`, `\c{c}`},
+	{`\Egrave`, "\\`{E}"},
+	{`\egrave`, "\\`{e}"},
+	{`\Eacute`, `\'{E}`},
+	{`\eacute`, `\'{e}`},
+	{`\Ecirc`, `\^{E}`},
+	{`\ecirc`, `\^{e}`},
+	{`\Euml`, `\"{E}`},
+	{`\euml`, `\"{e}`},
+	{`\Igrave`, "\\`{I}"},
+	{`\igrave`, "\\`{i}"},
+	{`\Iacute`, `\'{I}`},
+	{`\iacute`, `\'{i}`},
+	{`\Idot`, `\.{I}`},
+	{`\inodot`, `\i{}`},
+	{`\Icirc`, `\^{I}`},
+	{`\icirc`, `\^{i}`},
+	{`\Iuml`, `\"{I}`},
+	{`\iuml`, `\"{i}`},
+	{`\Ntilde`, `\~{N}`},
+	{`\ntilde`, `\~{n}`},
+	{`\Ograve`, "\\`{O}"},
+	{`\ograve`, "\\`{o}"},
+	{`\Oacute`, `\'{O}`},
+	{`\oacute`, `\'{o}`},
+	{`\Ocirc`, `\^{O}`},
+	{`\ocirc`, `\^{o}`},
+	{`\Otilde`, `\~{O}`},
+	{`\otilde`, `\~{o}`},
+	{`\Ouml`, `\"{O}`},
+	{`\ouml`, `\"{o}`},
+	{`\Oslash`, `\O{}`},
+	{`\oslash`, `\o{}`},
+	{`\OElig`, `\OE{}`},
+	{`\oelig`, `\oe{}`},
+	{`\Scaron`, `\v{S}`},
+	{`\scaron`, `\v{s}`},
+	{`\szlig`, `\ss{}`},
+	{`\Ugrave`, "\\`{U}"},
+	{`\ugrave`, "\\`{u}"},
+	{`\Uacute`, `\'{U}`},
+	{`\uacute`, `\'{u}`},
+	{`\Ucirc`, `\^{U}`},
+	{`\ucirc`, `\^{u}`},
+	{`\Uuml`, `\"{U}`},
+	{`\uuml`, `\"{u}`},
+	{`\Yacute`, `\'{Y}`},
+	{`\yacute`, `\'{y}`},
+	{`\Yuml`, `\"{Y}`},
+	{`\yuml`, `\"{y}`},
+	{`\fnof`, `\textit{f}`},
+	{`\real`, `\(\Re\)`},
+	{`\image`, `\(\Im\)`},
+	{`\weierp`, `\(\wp\)`},
+	{`\ell`, `\(\ell\)`},
+	{`\imath`, `\(\imath\)`},
+	{`\jmath`, `\(\jmath\)`},
+	{`\Alpha`, `A`},
+	{`\alpha`, `\(\alpha\)`},
+	{`\Beta`, `B`},
+	{`\beta`, `\(\beta\)`},
+	{`\Gamma`, `\(\Gamma\)`},
+	{`\gamma`, `\(\gamma\)`},
+	{`\Delta`, `\(\Delta\)`},
+	{`\delta`, `\(\delta\)`},
+	{`\Epsilon`, `E`},
+	{`\epsilon`, `\(\epsilon\)`},
+	{`\varepsilon`, `\(\varepsilon\)`},
+	{`\Zeta`, `Z`},
+	{`\zeta`, `\(\zeta\)`},
+	{`\Eta`, `H`},
+	{`\eta`, `\(\eta\)`},
+	{`\Theta`, `\(\Theta\)`},
+	{`\theta`, `\(\theta\)`},
+	{`\thetasym`, `\(\vartheta\)`},
+	{`\vartheta`, `\(\vartheta\)`},
+	{`\Iota`, `I`},
+	{`\iota`, `\(\iota\)`},
+	{`\Kappa`, `K`},
+	{`\kappa`, `\(\kappa\)`},
+	{`\Lambda`, `\(\Lambda\)`},
+	{`\lambda`, `\(\lambda\)`},
+	{`\Mu`, `M`},
+	{`\mu`, `\(\mu\)`},
+	{`\nu`, `\(\nu\)`},
+	{`\Nu`, `N`},
+	{`\Xi`, `\(\Xi\)`},
+	{`\xi`, `\(\xi\)`},
+	{`\Omicron`, `O`},
+	{`\omicron`, `\textit{o}`},
+	{`\Pi`, `\(\Pi\)`},
+	{`\pi`, `\(\pi\)`},
+	{`\Rho`, `P`},
+	{`\rho`, `\(\rho\)`},
+	{`\Sigma`, `\(\Sigma\)`},
+	{`\sigma`, `\(\sigma\)`},
+	{`\sigmaf`, `\(\varsigma\)`},
+	{`\varsigma`, `\(\varsigma\)`},
+	{`\Tau`, `T`},
+	{`\Upsilon`, `\(\Upsilon\)`},
+	{`\upsih`, `\(\Upsilon\)`},
+	{`\upsilon`, `\(\upsilon\)`},
+	{`\Phi`, `\(\Phi\)`},
+	{`\phi`, `\(\phi\)`},
+	{`\varphi`, `\(\varphi\)`},
+	{`\Chi`, `X`},
+	{`\chi`, `\(\chi\)`},
+	{`\acutex`, `\(\acute x\)`},
+	{`\Psi`, `\(\Psi\)`},
+	{`\psi`, `\(\psi\)`},
+	{`\tau`, `\(\tau\)`},
+	{`\Omega`, `\(\Omega\)`},
+	{`\omega`, `\(\omega\)`},
+	{`\piv`, `\(\varpi\)`},
+	{`\varpi`, `\(\varpi\)`},
+	{`\partial`, `\(\partial\)`},
+	{`\alefsym`, `\(\aleph\)`},
+	{`\aleph`, `\(\aleph\)`},
+	{`\gimel`, `\(\gimel\)`},
+	{`\beth`, `\(\beth\)`},
+	{`\dalet`, `\(\daleth\)`},
+	{`\ETH`, `\DH{}`},
+	{`\eth`, `\dh{}`},
+	{`\THORN`, `\TH{}`},
+	{`\thorn`, `\th{}`},
+	{`\dots`, `\dots{}`},
+	{`\cdots`, `\(\cdots\)`},
+	{`\hellip`, `\dots{}`},
+	{`\middot`, `\textperiodcentered{}`},
+	{`\iexcl`, "!`"},
+	{`\iquest`, "?`"},
+	{`\shy`, `\-`},
+	{`\ndash`, `--`},
+	{`\mdash`, `---`},
+	{`\quot`, `\textquotedbl{}`},
+	{`\acute`, `\textasciiacute{}`},
+	{`\ldquo`, `\textquotedblleft{}`},
+	{`\rdquo`, `\textquotedblright{}`},
+	{`\bdquo`, `\quotedblbase{}`},
+	{`\lsquo`, `\textquoteleft{}`},
+	{`\rsquo`, `\textquoteright{}`},
+	{`\sbquo`, `\quotesinglbase{}`},
+	{`\laquo`, `\guillemotleft{}`},
+	{`\raquo`, `\guillemotright{}`},
+	{`\lsaquo`, `\guilsinglleft{}`},
+	{`\rsaquo`, `\guilsinglright{}`},
+	{`\circ`, `\(\circ\)`},
+	{`\vert`, `\(\vert{}\)`},
+	{`\vbar`, `|`},
+	{`\brvbar`, `\textbrokenbar{}`},
+	{`\S`, `\S{}`},
+	{`\sect`, `\S{}`},
+	{`\amp`, `\&`},
+	{`\lt`, `\textless{}`},
+	{`\gt`, `\textgreater{}`},
+	{`\tilde`, `\textasciitilde{}`},
+	{`\slash`, `/`},
+	{`\plus`, `+`},
+	{`\under`, `\_`},
+	{`\equal`, `=`},
+	{`\asciicirc`, `\textasciicircum{}`},
+	{`\dagger`, `\textdagger{}`},
+	{`\dag`, `\dag{}`},
+	{`\Dagger`, `\textdaggerdbl{}`},
+	{`\ddag`, `\ddag{}`},
+	{`\nbsp`, `~`},
+	{`\ensp`, `\hspace*{.5em}`},
+	{`\emsp`, `\hspace*{1em}`},
+	{`\thinsp`, `\hspace*{.2em}`},
+	{`\curren`, `\textcurrency{}`},
+	{`\cent`, `\textcent{}`},
+	{`\pound`, `\pounds{}`},
+	{`\yen`, `\textyen{}`},
+	{`\euro`, `\texteuro{}`},
+	{`\EUR`, `\texteuro{}`},
+	{`\dollar`, `\$`},
+	{`\USD`, `\$`},
+	{`\copy`, `\textcopyright{}`},
+	{`\reg`, `\textregistered{}`},
+	{`\trade`, `\texttrademark{}`},
+	{`\minus`, `\(-\)`},
+	{`\pm`, `\(\pm\)`},
+	{`\plusmn`, `\textpm{}`},
+	{`\times`, `\(\times\)`},
+	{`\frasl`, `/`},
+	{`\colon`, `\(\colon\)`},
+	{`\div`, `\textdiv{}`},
+	{`\frac12`, `\textonehalf{}`},
+	{`\frac14`, `\textonequarter{}`},
+	{`\frac34`, `\textthreequarters{}`},
+	{`\permil`, `\textperthousand{}`},
+	{`\sup1`, `\textonesuperior{}`},
+	{`\sup2`, `\texttwosuperior{}`},
+	{`\sup3`, `\textthreesuperior{}`},
+	{`\radic`, `\(\sqrt{\,}\)`},
+	{`\sum`, `\(\sum\)`},
+	{`\prod`, `\(\prod\)`},
+	{`\micro`, `\textmu{}`},
+	{`\macr`, `\textasciimacron{}`},
+	{`\deg`, `\textdegree{}`},
+	{`\prime`, `\(\prime\)`},
+	{`\Prime`, `\(\prime{}\prime\)`},
+	{`\infin`, `\(\infty\)`},
+	{`\infty`, `\(\infty\)`},
+	{`\prop`, `\(\propto\)`},
+	{`\propto`, `\(\propto\)`},
+	{`\not`, `\textlnot{}`},
+	{`\neg`, `\(\neg\)`},
+	{`\land`, `\(\land\)`},
+	{`\wedge`, `\(\wedge\)`},
+	{`\lor`, `\(\lor\)`},
+	{`\vee`, `\(\vee\)`},
+	{`\cap`, `\(\cap\)`},
+	{`\cup`, `\(\cup\)`},
+	{`\smile`, `\(\smile\)`},
+	{`\frown`, `\(\frown\)`},
+	{`\int`, `\(\int\)`},
+	{`\therefore`, `\(\therefore\)`},
+	{`\there4`, `\(\therefore\)`},
+	{`\because`, `\(\because\)`},
+	{`\sim`, `\(\sim\)`},
+	{`\cong`, `\(\cong\)`},
+	{`\simeq`, `\(\simeq\)`},
+	{`\asymp`, `\(\asymp\)`},
+	{`\approx`, `\(\approx\)`},
+	{`\ne`, `\(\ne\)`},
+	{`\neq`, `\(\neq\)`},
+	{`\equiv`, `\(\equiv\)`},
+	{`\triangleq`, `\(\triangleq\)`},
+	{`\le`, `\(\le\)`},
+	{`\leq`, `\(\le\)`},
+	{`\ge`, `\(\ge\)`},
+	{`\geq`, `\(\ge\)`},
+	{`\lessgtr`, `\(\lessgtr\)`},
+	{`\lesseqgtr`, `\(\lesseqgtr\)`},
+	{`\ll`, `\(\ll\)`},
+	{`\Ll`, `\(\lll\)`},
+	{`\lll`, `\(\lll\)`},
+	{`\gg`, `\(\gg\)`},
+	{`\Gg`, `\(\ggg\)`},
+	{`\ggg`, `\(\ggg\)`},
+	{`\prec`, `\(\prec\)`},
+	{`\preceq`, `\(\preceq\)`},
+	{`\preccurlyeq`, `\(\preccurlyeq\)`},
+	{`\succ`, `\(\succ\)`},
+	{`\succeq`, `\(\succeq\)`},
+	{`\succcurlyeq`, `\(\succcurlyeq\)`},
+	{`\sub`, `\(\subset\)`},
+	{`\subset`, `\(\subset\)`},
+	{`\sup`, `\(\supset\)`},
+	{`\supset`, `\(\supset\)`},
+	{`\nsub`, `\(\not\subset\)`},
+	{`\sube`, `\(\subseteq\)`},
+	{`\nsup`, `\(\not\supset\)`},
+	{`\supe`, `\(\supseteq\)`},
+	{`\setminus`, `\(\setminus\)`},
+	{`\forall`, `\(\forall\)`},
+	{`\exist`, `\(\exists\)`},
+	{`\exists`, `\(\exists\)`},
+	{`\nexist`, `\(\nexists\)`},
+	{`\nexists`, `\(\nexists\)`},
+	{`\empty`, `\(\emptyset\)`},
+	{`\emptyset`, `\(\emptyset\)`},
+	{`\isin`, `\(\in\)`},
+	{`\in`, `\(\in\)`},
+	{`\notin`, `\(\notin\)`},
+	{`\ni`, `\(\ni\)`},
+	{`\nabla`, `\(\nabla\)`},
+	{`\ang`, `\(\angle\)`},
+	{`\angle`, `\(\angle\)`},
+	{`\perp`, `\(\perp\)`},
+	{`\parallel`, `\(\parallel\)`},
+	{`\sdot`, `\(\cdot\)`},
+	{`\cdot`, `\(\cdot\)`},
+	{`\lceil`, `\(\lceil\)`},
+	{`\rceil`, `\(\rceil\)`},
+	{`\lfloor`, `\(\lfloor\)`},
+	{`\rfloor`, `\(\rfloor\)`},
+	{`\lang`, `\(\langle\)`},
+	{`\rang`, `\(\rangle\)`},
+	{`\langle`, `\(\langle\)`},
+	{`\rangle`, `\(\rangle\)`},
+	{`\hbar`, `\(\hbar\)`},
+	{`\mho`, `\(\mho\)`},
+	{`\larr`, `\(\leftarrow\)`},
+	{`\leftarrow`, `\(\leftarrow\)`},
+	{`\gets`, `\(\gets\)`},
+	{`\lArr`, `\(\Leftarrow\)`},
+	{`\Leftarrow`, `\(\Leftarrow\)`},
+	{`\uarr`, `\(\uparrow\)`},
+	{`\uparrow`, `\(\uparrow\)`},
+	{`\uArr`, `\(\Uparrow\)`},
+	{`\Uparrow`, `\(\Uparrow\)`},
+	{`\rarr`, `\(\rightarrow\)`},
+	{`\to`, `\(\to\)`},
+	{`\rightarrow`, `\(\rightarrow\)`},
+	{`\rArr`, `\(\Rightarrow\)`},
+	{`\Rightarrow`, `\(\Rightarrow\)`},
+	{`\darr`, `\(\downarrow\)`},
+	{`\downarrow`, `\(\downarrow\)`},
+	{`\dArr`, `\(\Downarrow\)`},
+	{`\Downarrow`, `\(\Downarrow\)`},
+	{`\harr`, `\(\leftrightarrow\)`},
+	{`\leftrightarrow`, `\(\leftrightarrow\)`},
+	{`\hArr`, `\(\Leftrightarrow\)`},
+	{`\Leftrightarrow`, `\(\Leftrightarrow\)`},
+	{`\crarr`, `\(\hookleftarrow\)`},
+	{`\hookleftarrow`, `\(\hookleftarrow\)`},
+	{`\arccos`, `\(\arccos\)`},
+	{`\arcsin`, `\(\arcsin\)`},
+	{`\arctan`, `\(\arctan\)`},
+	{`\arg`, `\(\arg\)`},
+	{`\cos`, `\(\cos\)`},
+	{`\cosh`, `\(\cosh\)`},
+	{`\cot`, `\(\cot\)`},
+	{`\coth`, `\(\coth\)`},
+	{`\csc`, `\(\csc\)`},
+	{`\det`, `\(\det\)`},
+	{`\dim`, `\(\dim\)`},
+	{`\exp`, `\(\exp\)`},
+	{`\gcd`, `\(\gcd\)`},
+	{`\hom`, `\(\hom\)`},
+	{`\inf`, `\(\inf\)`},
+	{`\ker`, `\(\ker\)`},
+	{`\lg`, `\(\lg\)`},
+	{`\lim`, `\(\lim\)`},
+	{`\liminf`, `\(\liminf\)`},
+	{`\limsup`, `\(\limsup\)`},
+	{`\ln`, `\(\ln\)`},
+	{`\log`, `\(\log\)`},
+	{`\max`, `\(\max\)`},
+	{`\min`, `\(\min\)`},
+	{`\Pr`, `\(\Pr\)`},
+	{`\sec`, `\(\sec\)`},
+	{`\sin`, `\(\sin\)`},
+	{`\sinh`, `\(\sinh\)`},
+	{`\tan`, `\(\tan\)`},
+	{`\tanh`, `\(\tanh\)`},
+	{`\bull`, `\textbullet{}`},
+	{`\bullet`, `\textbullet{}`},
+	{`\star`, `\(\star\)`},
+	{`\lowast`, `\(\ast\)`},
+	{`\ast`, `\(\ast\)`},
+	{`\odot`, `\(\odot\)`},
+	{`\oplus`, `\(\oplus\)`},
+	{`\otimes`, `\(\otimes\)`},
+	{`\check`, `\(\checkmark\)`},
+	{`\checkmark`, `\(\checkmark\)`},
+	{`\para`, `\P{}`},
+	{`\ordf`, `\textordfeminine{}`},
+	{`\ordm`, `\textordmasculine{}`},
+	{`\cedil`, `\c{}`},
+	{`\oline`, `\(\overline{~}\)`},
+	{`\uml`, `\textasciidieresis{}`},
+	{`\zwnj`, `\/{}`},
+	{`\zwj`, ``},
+	{`\lrm`, ``},
+	{`\rlm`, ``},
+	{`\smiley`, `\(\ddot\smile\)`},
+	{`\blacksmile`, `\(\ddot\smile\)`},
+	{`\sad`, `\(\ddot\frown\)`},
+	{`\frowny`, `\(\ddot\frown\)`},
+	{`\clubs`, `\(\clubsuit\)`},
+	{`\clubsuit`, `\(\clubsuit\)`},
+	{`\spades`, `\(\spadesuit\)`},
+	{`\spadesuit`, `\(\spadesuit\)`},
+	{`\hearts`, `\(\heartsuit\)`},
+	{`\heartsuit`, `\(\heartsuit\)`},
+	{`\diams`, `\(\diamondsuit\)`},
+	{`\diamondsuit`, `\(\diamondsuit\)`},
+	{`\diamond`, `\(\diamondsuit\)`},
+	{`\Diamond`, `\(\diamondsuit\)`},
+	{`\loz`, `\(\lozenge\)`},
+}
@@ -0,0 +1,742 @@
+package org
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	u "net/url"
+)
+
+// LaTeXWriter exports an org document into a LaTeX document.
+// The document class and preamble can be customized using #+LATEX_CLASS, #+LATEX_CLASS_OPTIONS and #+LATEX_HEADER,
+// tables, images and other captioned elements using #+ATTR_LATEX.
+type LaTeXWriter struct {
+	ExtendingWriter Writer
+	// Minted determines whether SRC blocks are written as minted rather than listings (lstlisting) environments.
+	Minted bool
+	// Diagnostics contains the problems found while writing (e.g. bad macros). They are not added to Document.Diagnostics.
+	Diagnostics []Diagnostic
+
+	strings.Builder
+	document  *Document
+	escape    bool   // escape is unset while writing the content of raw blocks and LaTeX fragments.
+	label     string // label is the label of the named node that is written next - it is written after its caption.
+	footnotes *footnotes
+}
+
+var emphasisLaTeXCommands = map[string]string{
+	"/":   `\emph`,
+	"*":   `\textbf`,
+	"+":   `\sout`,
+	"_":   `\uline`,
+	"~":   `\texttt`,
+	"=":   `\texttt`,
+	"_{}": `\textsubscript`,
+	"^{}": `\textsuperscript`,
+}
+
+var latexListEnvironments = map[string]string{
+	"unordered":   "itemize",
+	"ordered":     "enumerate",
+	"descriptive": "description",
+}
+
+var latexListItemStatuses = map[string]string{
+	" ": `\(\square\)`,
+	"-": `\(\boxminus\)`,
+	"X": `\(\boxtimes\)`,
+}
+
+var latexURLReplacer = strings.NewReplacer(`#`, `\#`, `%`, `\%`)
+
+var latexSectionCommands = []string{"section", "subsection", "subsubsection", "paragraph", "subparagraph"}
+
+var latexPackages = []string{
+	`[utf8]{inputenc}`,
+	`[T1]{fontenc}`,
+	`{graphicx}`,
+	`{longtable}`,
+	`[normalem]{ulem}`,
+	`{amsmath}`,
+	`{amssymb}`,
+}
+
+// latexListingsLanguages maps org source block languages to the names of the languages supported by listings.
+// Other languages are written without language as listings fails on unknown languages.
+var latexListingsLanguages = map[string]string{
+	"awk": "Awk", "bash": "bash", "c": "C", "c++": "C++", "cpp": "C++", "elisp": "Lisp", "emacs-lisp": "Lisp",
+	"fortran": "Fortran", "haskell": "Haskell", "html": "HTML", "java": "Java", "latex": "TeX", "lisp": "Lisp",
+	"lua": "Lua", "make": "make", "makefile": "make", "matlab": "Matlab", "pascal": "Pascal", "perl": "Perl",
+	"php": "PHP", "python": "Python", "r": "R", "ruby": "Ruby", "sh": "bash", "shell": "bash", "sql": "SQL",
+	"tcl": "tcl", "tex": "TeX", "xml": "XML",
+}
+
+// latexMintedLanguages maps org source block languages to pygments lexers where the names differ.
+var latexMintedLanguages = map[string]string{
+	"elisp": "common-lisp", "emacs-lisp": "common-lisp", "sh": "bash", "shell": "bash",
+}
+
+func NewLaTeXWriter() *LaTeXWriter {
+	return &LaTeXWriter{
+		document: &Document{Configuration: New()},
+		escape:   true,
+		footnotes: &footnotes{
+			mapping: map[string]int{},
+			unused:  map[string]*FootnoteDefinition{},
+		},
+	}
+}
+
+func (w *LaTeXWriter) WriterWithExtensions() Writer {
+	if w.ExtendingWriter != nil {
+		return w.ExtendingWriter
+	}
+	return w
+}
+
+func (w *LaTeXWriter) WriteNodesAsString(nodes ...Node) string {
+	original := w.Builder
+	w.Builder = strings.Builder{}
+	WriteNodes(w, nodes...)
+	out := w.String()
+	w.Builder = original
+	return out
+}
+
+func (w *LaTeXWriter) Before(d *Document) {
+	w.document = d
+	// footnotes are written where they are referenced - i.e. the definitions must be known beforehand
+	w.footnotes.collectDefinitions(d.Nodes)
+	class, options := d.Get("LATEX_CLASS"), d.Get("LATEX_CLASS_OPTIONS")
+	if class == "" {
+		class = "article"
+	}
+	if options == "" {
+		options = "[11pt]"
+	}
+	w.WriteString(`\documentclass` + options + "{" + class + "}\n")
+	for _, p := range latexPackages {
+		w.WriteString(`\usepackage` + p + "\n")
+	}
+	if w.Minted {
+		w.WriteString(`\usepackage{minted}` + "\n")
+	} else {
+		w.WriteString(`\usepackage{listings}` + "\n")
+	}
+	// hyperref should be loaded last
+	w.WriteString(`\usepackage{hyperref}` + "\n")
+	if header := d.Get("LATEX_HEADER"); header != "" {
+		w.WriteString(header + "\n")
+	}
+	title := d.Get("TITLE")
+	if title != "" && w.document.GetOption("title") != "nil" {
+		titleDocument := d.Parse(strings.NewReader(title), d.Path)
+		if titleDocument.Error == nil {
+			title = strings.TrimSpace(w.writeInline(titleDocument.Nodes))
+		} else {
+			title = latexEscapeReplacer.Replace(title)
+		}
+		w.WriteString(`\title{` + title + "}\n")
+		if author := d.Get("AUTHOR"); author != "" {
+			w.WriteString(`\author{` + latexEscapeReplacer.Replace(author) + "}\n")
+		}
+		if date := d.Get("DATE"); date != "" {
+			w.WriteString(`\date{` + latexEscapeReplacer.Replace(date) + "}\n")
+		}
+	} else {
+		title = ""
+	}
+	w.WriteString(`\begin{document}` + "\n")
+	if title != "" {
+		w.startBlock()
+		w.WriteString(`\maketitle` + "\n")
+	}
+	if w.document.GetOption("toc") != "nil" {
+		maxLvl, _ := strconv.Atoi(w.document.GetOption("toc"))
+		w.writeTableOfContents(maxLvl)
+	}
+}
+
+func (w *LaTeXWriter) After(d *Document) {
+	w.startBlock()
+	w.WriteString(`\end{document}` + "\n")
+}
+
+func (w *LaTeXWriter) WriteComment(Comment)               {}
+func (w *LaTeXWriter) WritePropertyDrawer(PropertyDrawer) {}
+
+func (w *LaTeXWriter) WriteKeyword(k Keyword) {
+	switch k.Key {
+	case "LATEX":
+		w.startBlock()
+		w.WriteString(k.Value + "\n")
+	case "TOC":
+		if m := tocHeadlineMaxLvlRegexp.FindStringSubmatch(k.Value); m != nil {
+			maxLvl, _ := strconv.Atoi(m[1])
+			w.writeTableOfContents(maxLvl)
+		}
+	}
+}
+
+func (w *LaTeXWriter) WriteInclude(i Include) {
+	WriteNodes(w, i.Resolve())
+}
+
+func (w *LaTeXWriter) writeTableOfContents(maxLvl int) {
+	w.startBlock()
+	if maxLvl != 0 {
+		w.WriteString(fmt.Sprintf(`\setcounter{tocdepth}{%d}`, maxLvl) + "\n")
+	}
+	w.WriteString(`\tableofcontents` + "\n")
+}
+
+func (w *LaTeXWriter) WriteHeadline(h Headline) {
+	if h.IsExcluded(w.document) {
+		return
+	}
+	sections := latexSectionCommands
+	if class := w.document.Get("LATEX_CLASS"); class == "book" || class == "report" {
+		sections = append([]string{"chapter"}, sections...)
+	}
+	command := sections[len(sections)-1]
+	if h.Lvl <= len(sections) {
+		command = sections[h.Lvl-1]
+	}
+	title, shortTitle := w.headlineTitle(h, h.Title), ""
+	// footnotes cannot be part of the table of contents - use a short title without them for it
+	withoutFootnotes := Transform(h.Title, func(n Node) []Node {
+		if _, ok := n.(FootnoteLink); ok {
+			return nil
+		}
+		return []Node{n}
+	})
+	if len(withoutFootnotes) != len(h.Title) {
+		shortTitle = "[" + w.headlineTitle(h, withoutFootnotes) + "]"
+	}
+	w.startBlock()
+	if w.document.GetOption("num") == "nil" {
+		// unnumbered sections are not part of the table of contents unless they are added explicitly
+		w.WriteString(fmt.Sprintf(`\%s*{%s}`, command, title) + "\n")
+		if w.document.GetOption("toc") != "nil" {
+			w.WriteString(fmt.Sprintf(`\addcontentsline{toc}{%s}{%s}`, command, w.headlineTitle(h, withoutFootnotes)) + "\n")
+		}
+	} else {
+		w.WriteString(fmt.Sprintf(`\%s%s{%s}`, command, shortTitle, title) + "\n")
+	}
+	w.WriteString(`\label{` + h.ID() + "}\n")
+	if entries := h.Planning(); w.document.GetOption("p") != "nil" && len(entries) != 0 {
+		planning := make([]string, len(entries))
+		for i, p := range entries {
+			planning[i] = fmt.Sprintf(`\textbf{%s:} \textit{%s}`, p.Keyword, latexEscapeReplacer.Replace(String(*p.Timestamp)))
+		}
+		w.startBlock()
+		w.WriteString(`\noindent` + strings.Join(planning, " ") + "\n")
+	}
+	WriteNodes(w, h.Children...)
+}
+
+func (w *LaTeXWriter) headlineTitle(h Headline, title []Node) string {
+	out := ""
+	if w.document.GetOption("todo") != "nil" && h.Status != "" {
+		out += `\textbf{` + h.Status + `} `
+	}
+	if w.document.GetOption("pri") != "nil" && h.Priority != "" {
+		out += `\framebox{\#` + h.Priority + `} `
+	}
+	out += strings.TrimSpace(w.WriteNodesAsString(title...))
+	if w.document.GetOption("tags") != "nil" && len(h.Tags) != 0 {
+		out += `\hfill{}\textsc{` + latexEscapeReplacer.Replace(strings.Join(h.Tags, ":")) + `}`
+	}
+	return out
+}
+
+func (w *LaTeXWriter) WriteBlock(b Block) {
+	content, params := w.blockContent(b.Name, b.Children), b.ParameterMap()
+	switch b.Name {
+	case "SRC":
+		if params[":exports"] == "results" || params[":exports"] == "none" {
+			break
+		}
+		if params[":noweb"] == "strip-export" {
+			content = regexp.MustCompile(`<<[^>]+>>`).ReplaceAllString(content, "")
+		}
+		lang := ""
+		if len(b.Parameters) >= 1 {
+			lang = strings.ToLower(b.Parameters[0])
+		}
+		w.writeSourceBlock(content, lang)
+	case "EXAMPLE":
+		w.writeEnvironment("verbatim", "", content)
+	case "EXPORT":
+		if len(b.Parameters) >= 1 && strings.ToLower(b.Parameters[0]) == "latex" {
+			w.startBlock()
+			w.WriteString(content + "\n")
+		}
+	case "VERSE":
+		lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+		for i := 0; i < len(lines)-1; i++ {
+			if strings.TrimSpace(lines[i]) != "" && strings.TrimSpace(lines[i+1]) != "" {
+				lines[i] += ` \\`
+			}
+		}
+		w.writeEnvironment("verse", "", strings.Join(lines, "\n"))
+	default:
+		// QUOTE and CENTER are LaTeX environments as well - other special blocks are written as environments of the same name
+		w.writeEnvironment(strings.ToLower(b.Name), "", content)
+	}
+	if b.Result != nil && params[":exports"] != "code" && params[":exports"] != "none" {
+		WriteNodes(w, b.Result)
+	}
+}
+
+func (w *LaTeXWriter) writeSourceBlock(content, lang string) {
+	if w.Minted {
+		if lang == "" {
+			lang = "text"
+		} else if lexer, ok := latexMintedLanguages[lang]; ok {
+			lang = lexer
+		}
+		w.writeEnvironment("minted", "{"+lang+"}", content)
+	} else if language, ok := latexListingsLanguages[lang]; ok {
+		w.writeEnvironment("lstlisting", "[language="+language+"]", content)
+	} else {
+		w.writeEnvironment("lstlisting", "", content)
+	}
+}
+
+func (w *LaTeXWriter) WriteResult(r Result) { WriteNodes(w, r.Node) }
+
+func (w *LaTeXWriter) WriteLatexBlock(b LatexBlock) {
+	w.startBlock()
+	w.WriteString(strings.TrimSpace(w.rawContent(b.Content)) + "\n")
+}
+
+func (w *LaTeXWriter) WriteInlineBlock(b InlineBlock) {
+	content := w.blockContent(strings.ToUpper(b.Name), b.Children)
+	switch b.Name {
+	case "src":
+		w.WriteString(`\texttt{` + latexEscapeReplacer.Replace(content) + `}`)
+	case "export":
+		if strings.ToLower(b.Parameters[0]) == "latex" {
+			w.WriteString(content)
+		}
+	}
+}
+
+func (w *LaTeXWriter) WriteExample(e Example) {
+	lines := []string{}
+	for _, n := range e.Children {
+		lines = append(lines, w.rawContent([]Node{n}))
+	}
+	w.writeEnvironment("verbatim", "", strings.Join(lines, "\n"))
+}
+
+func (w *LaTeXWriter) WriteDrawer(d Drawer) {
+	WriteNodes(w, d.Children...)
+}
+
+func (w *LaTeXWriter) WriteClock(c Clock) {
+	if w.document.GetOption("c") == "nil" {
+		return
+	}
+	w.startBlock()
+	w.WriteString(`\noindent\textbf{CLOCK:} \textit{` + latexEscapeReplacer.Replace(String(c.Timestamp)) + `}`)
+	if c.Timestamp.End != nil {
+		w.WriteString(" (" + formatClockDuration(c.Duration) + ")")
+	}
+	w.WriteString("\n")
+}
+
+func (w *LaTeXWriter) WriteLogEntry(e LogEntry) { WriteNodes(w, e.Item) }
+
+// WriteFootnoteDefinition does nothing - footnotes are written where they are referenced (see WriteFootnoteLink).
+func (w *LaTeXWriter) WriteFootnoteDefinition(f FootnoteDefinition) {}
+
+// WriteFootnoteLink writes the first reference of a footnote as \footnote. Further references of named footnotes
+// refer to the number of the footnote.
+func (w *LaTeXWriter) WriteFootnoteLink(l FootnoteLink) {
+	if w.document.GetOption("f") == "nil" {
+		return
+	}
+	count := len(w.footnotes.list)
+	i := w.footnotes.add(l)
+	if i < count {
+		w.WriteString(`\textsuperscript{\ref{fn:` + l.Name + `}}`)
+		return
+	}
+	definition := w.footnotes.list[i]
+	if definition == nil {
+		w.Diagnostics = append(w.Diagnostics, w.document.diagnostic(SeverityWarning, CodeMissingFootnoteDefinition, w.footnotes.spans[i],
+			"Missing footnote definition for [fn:%s] (#%d)", l.Name, i+1))
+		return
+	}
+	w.WriteString(`\footnote{` + strings.TrimSpace(w.WriteNodesAsString(definition.Children...)))
+	if l.Name != "" {
+		w.WriteString(`\label{fn:` + l.Name + `}`)
+	}
+	w.WriteString("}")
+}
+
+func (w *LaTeXWriter) WriteList(l List) {
+	environment := latexListEnvironments[l.Kind]
+	w.startBlock()
+	w.WriteString(`\begin{` + environment + "}\n")
+	WriteNodes(w, l.Items...)
+	w.WriteString(`\end{` + environment + "}\n")
+}
+
+func (w *LaTeXWriter) WriteListItem(li ListItem) {
+	w.writeListItem(latexListItemStatuses[li.Status], li.Children)
+}
+
+func (w *LaTeXWriter) WriteDescriptiveListItem(di DescriptiveListItem) {
+	term := strings.TrimSpace(w.WriteNodesAsString(di.Term...))
+	if status := latexListItemStatuses[di.Status]; status != "" {
+		term = strings.TrimSpace(status + " " + term)
+	}
+	w.writeListItem(term, di.Details)
+}
+
+func (w *LaTeXWriter) writeListItem(label string, children []Node) {
+	content := strings.TrimSpace(w.WriteNodesAsString(children...))
+	w.WriteString(`\item`)
+	if label != "" {
+		w.WriteString("[{" + label + "}]")
+	} else if strings.HasPrefix(content, "[") {
+		// brackets at the start of the item would be parsed as its label
+		w.WriteString("{}")
+	}
+	if content != "" {
+		w.WriteString(" " + content)
+	}
+	w.WriteString("\n")
+}
+
+func (w *LaTeXWriter) WriteTable(t Table) {
+	w.writeTable(t, nil, "", "")
+}
+
+// writeTable writes the table as tabular - or as the :environment of the #+ATTR_LATEX attributes (e.g. longtable).
+// Captioned tables are written as table floats.
+func (w *LaTeXWriter) writeTable(t Table, attributes map[string]string, caption, label string) {
+	if len(t.ColumnInfos) == 0 {
+		return
+	}
+	environment, align := attributes["environment"], attributes["align"]
+	if environment == "" {
+		environment = "tabular"
+	}
+	if align == "" {
+		for _, info := range t.ColumnInfos {
+			switch info.Align {
+			case "center":
+				align += "c"
+			case "right":
+				align += "r"
+			default:
+				align += "l"
+			}
+		}
+	}
+	arguments := "{" + align + "}"
+	if width := attributes["width"]; width != "" {
+		arguments = "{" + width + "}" + arguments
+	} else if environment == "tabularx" {
+		arguments = `{\linewidth}` + arguments
+	}
+	lines := []string{}
+	if environment == "longtable" && caption != "" {
+		// longtables are not floats - the caption is part of the table
+		lines, caption = append(lines, latexCaption(caption, label)+` \\`), ""
+	}
+	for _, row := range t.Rows {
+		if len(row.Columns) == 0 {
+			lines = append(lines, `\hline`)
+			continue
+		} else if row.IsSpecial {
+			continue
+		}
+		cells := make([]string, len(t.ColumnInfos))
+		for i, column := range row.Columns {
+			if i < len(cells) {
+				cells[i] = strings.TrimSpace(w.WriteNodesAsString(column.Children...))
+			}
+		}
+		lines = append(lines, strings.Join(cells, " & ")+` \\`)
+	}
+	table := `\begin{` + environment + "}" + arguments + "\n" + strings.Join(lines, "\n") + "\n" + `\end{` + environment + "}"
+	switch {
+	case environment == "longtable":
+		w.startBlock()
+		w.WriteString(table + "\n")
+	case caption != "":
+		// table captions go above the table
+		w.writeFloat("table", attributes["placement"], latexCaption(caption, label)+"\n"+table)
+	default:
+		w.writeEnvironment("center", "", table)
+	}
+}
+
+func (w *LaTeXWriter) WriteHorizontalRule(HorizontalRule) {
+	w.startBlock()
+	w.WriteString(`\noindent\rule{\linewidth}{0.5pt}` + "\n")
+}
+
+func (w *LaTeXWriter) WriteParagraph(p Paragraph) {
+	content := strings.TrimRight(strings.TrimLeft(w.WriteNodesAsString(p.Children...), "\n"), " \n")
+	if content == "" {
+		return
+	}
+	lines := strings.Split(content, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimLeft(lines[i], " \t")
+	}
+	w.startBlock()
+	w.WriteString(strings.Join(lines, "\n") + "\n")
+}
+
+// WriteNodeWithMeta writes tables and standalone images using their #+ATTR_LATEX attributes
+// (e.g. :environment, :align and :placement for tables and :width, :height, :options and :placement for images).
+// Captioned nodes are written as floats - table for tables and figure for everything else.
+func (w *LaTeXWriter) WriteNodeWithMeta(n NodeWithMeta) {
+	attributes, label := map[string]string{}, w.label
+	w.label = ""
+	for _, kvs := range n.Meta.LaTeXAttributes {
+		for i := 0; i < len(kvs)-1; i += 2 {
+			attributes[strings.TrimPrefix(kvs[i], ":")] = kvs[i+1]
+		}
+	}
+	captions := []string{}
+	for _, ns := range n.Meta.Caption {
+		captions = append(captions, strings.TrimSpace(w.WriteNodesAsString(ns...)))
+	}
+	caption := strings.Join(captions, " ")
+	if t, ok := n.Node.(Table); ok {
+		w.writeTable(t, attributes, caption, label)
+		return
+	}
+	content, isImage := "", false
+	if p, ok := n.Node.(Paragraph); ok && len(p.Children) == 1 && isImageOrVideoLink(p.Children[0]) {
+		if l := p.Children[0].(RegularLink); l.Kind() == "image" && l.Description == nil {
+			content, isImage = w.includeGraphics(w.url(l), attributes), true
+		}
+	}
+	if !isImage {
+		content = strings.TrimSpace(w.WriteNodesAsString(n.Node))
+	}
+	if caption != "" {
+		w.writeFloat("figure", attributes["placement"], `\centering`+"\n"+content+"\n"+latexCaption(caption, label))
+	} else if isImage {
+		w.writeEnvironment("center", "", content)
+	} else {
+		w.startBlock()
+		w.WriteString(content + "\n")
+	}
+}
+
+func (w *LaTeXWriter) WriteNodeWithName(n NodeWithName) {
+	if m, ok := n.Node.(NodeWithMeta); ok && len(m.Meta.Caption) != 0 {
+		w.label = targetID(n.Name)
+		WriteNodes(w, n.Node)
+		return
+	}
+	w.startBlock()
+	w.WriteString(`\phantomsection\label{` + targetID(n.Name) + "}\n")
+	WriteNodes(w, n.Node)
+}
+
+func (w *LaTeXWriter) WriteText(t Text) {
+	if !w.escape {
+		w.WriteString(t.Content)
+	} else if w.document.GetOption("e") == "nil" || t.IsRaw {
+		w.WriteString(latexEscapeReplacer.Replace(t.Content))
+	} else {
+		w.WriteString(latexReplacer.Replace(t.Content))
+	}
+}
+
+func (w *LaTeXWriter) WriteEmphasis(e Emphasis) {
+	command, ok := emphasisLaTeXCommands[e.Kind]
+	if !ok {
+		panic(fmt.Sprintf("bad emphasis %#v", e))
+	}
+	if e.Kind == "~" || e.Kind == "=" {
+		w.WriteString(command + "{" + latexEscapeReplacer.Replace(w.rawContent(e.Content)) + "}")
+		return
+	}
+	w.WriteString(command + "{")
+	WriteNodes(w, e.Content...)
+	w.WriteString("}")
+}
+
+func (w *LaTeXWriter) WriteLatexFragment(l LatexFragment) {
+	w.WriteString(l.OpeningPair + w.rawContent(l.Content) + l.ClosingPair)
+}
+
+func (w *LaTeXWriter) WriteStatisticToken(s StatisticToken) {
+	w.WriteString(`\texttt{[` + latexEscapeReplacer.Replace(s.Content) + `]}`)
+}
+
+func (w *LaTeXWriter) WriteLineBreak(l LineBreak) {
+	if w.document.GetOption("ealb") == "nil" || !l.BetweenMultibyteCharacters {
+		w.WriteString(strings.Repeat("\n", l.Count))
+	}
+}
+
+func (w *LaTeXWriter) WriteExplicitLineBreak(ExplicitLineBreak) {
+	w.WriteString(`\\` + "\n")
+}
+
+func (w *LaTeXWriter) WriteTimestamp(t Timestamp) {
+	if w.document.GetOption("<") != "nil" {
+		w.WriteString(`\textit{` + latexEscapeReplacer.Replace(String(t)) + `}`)
+	}
+}
+
+func (w *LaTeXWriter) WriteRegularLink(l RegularLink) {
+	if id, title, ok := w.document.ResolveInternalLink(l); ok {
+		description := l.Description
+		if description == nil {
+			description = title
+		}
+		w.WriteString(fmt.Sprintf(`\hyperref[%s]{%s}`, id, strings.TrimSpace(w.WriteNodesAsString(description...))))
+		return
+	}
+	url := w.url(l)
+	switch {
+	case l.Kind() == "image" && l.Description == nil:
+		w.WriteString(w.includeGraphics(url, nil))
+	case l.Kind() == "image":
+		description := strings.TrimPrefix(String(l.Description...), "file:")
+		w.WriteString(`\href{` + escapeLaTeXURL(url) + "}{" + w.includeGraphics(description, nil) + "}")
+	case l.Description == nil:
+		w.WriteString(`\url{` + url + "}")
+	default:
+		w.WriteString(`\href{` + escapeLaTeXURL(url) + "}{" + w.WriteNodesAsString(l.Description...) + "}")
+	}
+}
+
+// url returns the url of the link - links to .org files are turned into links to the .pdf files
+// compiled from the exported .tex files.
+func (w *LaTeXWriter) url(l RegularLink) string {
+	url := l.URL
+	if l.Protocol == "file" {
+		url = url[len("file:"):]
+	}
+	if isRelative := l.Protocol == "file" || l.Protocol == ""; isRelative {
+		// search options (e.g. ::#custom-id) cannot be resolved in other documents
+		if i := strings.Index(url, "::"); i != -1 {
+			url = url[:i]
+		}
+		if strings.HasSuffix(url, ".org") {
+			url = strings.TrimSuffix(url, ".org") + ".pdf"
+		}
+	}
+	if prefix := w.document.Links[l.Protocol]; prefix != "" {
+		if tag := strings.TrimPrefix(l.URL, l.Protocol+":"); strings.Contains(prefix, "%s") || strings.Contains(prefix, "%h") {
+			url = strings.ReplaceAll(strings.ReplaceAll(prefix, "%s", tag), "%h", u.QueryEscape(tag))
+		} else {
+			url = prefix + tag
+		}
+	} else if prefix := w.document.Links[l.URL]; prefix != "" {
+		url = strings.ReplaceAll(strings.ReplaceAll(prefix, "%s", ""), "%h", "")
+	}
+	return url
+}
+
+func (w *LaTeXWriter) includeGraphics(path string, attributes map[string]string) string {
+	options := []string{}
+	if width := attributes["width"]; width != "" {
+		options = append(options, "width="+width)
+	}
+	if height := attributes["height"]; height != "" {
+		options = append(options, "height="+height)
+	}
+	if len(options) == 0 {
+		options = append(options, `width=.9\linewidth`)
+	}
+	if o := attributes["options"]; o != "" {
+		options = append(options, o)
+	}
+	return `\includegraphics[` + strings.Join(options, ",") + "]{" + path + "}"
+}
+
+func (w *LaTeXWriter) WriteTarget(t Target) {
+	w.WriteString(`\phantomsection\label{` + targetID(t.Name) + "}")
+}
+
+func (w *LaTeXWriter) WriteRadioTarget(t RadioTarget) {
+	w.WriteString(`\phantomsection\label{` + targetID(t.Name) + "}" + latexEscapeReplacer.Replace(t.Name))
+}
+
+func (w *LaTeXWriter) WriteMacro(m Macro) {
+	if macro := w.document.Macros[m.Name]; macro != "" {
+		for i, param := range m.Parameters {
+			macro = strings.Replace(macro, fmt.Sprintf("$%d", i+1), param, -1)
+		}
+		macroDocument := w.document.Parse(strings.NewReader(macro), w.document.Path)
+		if macroDocument.Error != nil {
+			w.Diagnostics = append(w.Diagnostics, w.document.diagnostic(SeverityWarning, CodeBadMacro, m.Span, "bad macro: %s -> %s: %v", m.Name, macro, macroDocument.Error))
+		}
+		w.WriteString(strings.TrimSpace(w.writeInline(macroDocument.Nodes)))
+	}
+}
+
+// writeInline returns nodes written as inline LaTeX - a single paragraph is written as its children.
+func (w *LaTeXWriter) writeInline(nodes []Node) string {
+	if len(nodes) == 1 {
+		if p, ok := nodes[0].(Paragraph); ok {
+			return w.WriteNodesAsString(p.Children...)
+		}
+	}
+	return w.WriteNodesAsString(nodes...)
+}
+
+// startBlock separates the block that is written next from the previous one by a blank line.
+func (w *LaTeXWriter) startBlock() {
+	if out := w.String(); out != "" && !strings.HasSuffix(out, "\n\n") {
+		w.WriteString("\n")
+	}
+}
+
+func (w *LaTeXWriter) writeEnvironment(name, arguments, content string) {
+	w.startBlock()
+	w.WriteString(`\begin{` + name + "}" + arguments + "\n" + strings.Trim(content, "\n") + "\n" + `\end{` + name + "}\n")
+}
+
+func (w *LaTeXWriter) writeFloat(name, placement, content string) {
+	if placement == "" {
+		placement = "[htbp]"
+	}
+	w.writeEnvironment(name, placement, content)
+}
+
+// rawContent returns nodes written without escaping LaTeX (e.g. for raw blocks and LaTeX fragments).
+func (w *LaTeXWriter) rawContent(nodes []Node) string {
+	escape := w.escape
+	w.escape = false
+	out := w.WriteNodesAsString(nodes...)
+	w.escape = escape
+	return out
+}
+
+func (w *LaTeXWriter) blockContent(name string, children []Node) string {
+	if isRawTextBlock(name) {
+		return strings.TrimRightFunc(strings.TrimLeftFunc(w.rawContent(children), IsNewLineChar), unicode.IsSpace)
+	}
+	return w.WriteNodesAsString(children...)
+}
+
+func latexCaption(caption, label string) string {
+	if label != "" {
+		return `\caption{` + caption + `\label{` + label + `}}`
+	}
+	return `\caption{` + caption + `}`
+}
+
+// escapeLaTeXURL escapes the characters of url that would otherwise be interpreted by LaTeX
+// in the argument of \href.
+func escapeLaTeXURL(url string) string { return latexURLReplacer.Replace(url) }
@@ -0,0 +1,86 @@
+package org
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLaTeXWriter(t *testing.T) {
+	testWriter(t, func() Writer { return NewLaTeXWriter() }, ".tex")
+}
+
+func TestLaTeXWriterSettings(t *testing.T) {
+	input := `#+LATEX_CLASS: report
+#+LATEX_CLASS_OPTIONS: [a4paper]
+#+LATEX_HEADER: \usepackage{booktabs}
+#+OPTIONS: toc:nil
+
+* Chapter
+#+NAME: results
+#+CAPTION: Results
+#+ATTR_LATEX: :environment longtable :align lr
+| a | 1 |
+
+#+ATTR_LATEX: :width 5cm
+[[file:plot.png]]
+
+#+BEGIN_SRC go
+fmt.Println("50% & more")
+#+END_SRC
+`
+	w := NewLaTeXWriter()
+	w.Minted = true
+	out, err := New().Silent().Parse(strings.NewReader(input), "./test.org").Write(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`\documentclass[a4paper]{report}`,
+		`\usepackage{minted}`,
+		"\\usepackage{hyperref}\n\\usepackage{booktabs}\n",
+		"\\chapter{Chapter}\n",
+		"\\begin{longtable}{lr}\n\\caption{Results\\label{results}} \\\\\na & 1 \\\\\n\\end{longtable}",
+		"\\begin{center}\n\\includegraphics[width=5cm]{plot.png}\n\\end{center}",
+		"\\begin{minted}{go}\nfmt.Println(\"50% & more\")\n\\end{minted}",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected output to contain %q:\n%s", expected, out)
+		}
+	}
+}
+
+func TestLaTeXWriterUnnumbered(t *testing.T) {
+	input := `#+OPTIONS: num:nil
+
+* Section[fn:1]
+** Subsection
+
+[fn:1] note
+`
+	out, err := New().Silent().Parse(strings.NewReader(input), "./test.org").Write(NewLaTeXWriter())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"\\section*{Section\\footnote{note\\label{fn:1}}}\n\\addcontentsline{toc}{section}{Section}\n",
+		"\\subsection*{Subsection}\n\\addcontentsline{toc}{subsection}{Subsection}\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected output to contain %q:\n%s", expected, out)
+		}
+	}
+}
+
+func TestLaTeXEntities(t *testing.T) {
+	for input, expected := range map[string]string{
+		`\alpha and \alpha{}`:    `\(\alpha\) and \(\alpha\)`,
+		`\angle not \ang + le`:   `\(\angle\) not \(\angle\) + le`,
+		`50% of $5 & #1_a ~ ^`:   `50\% of \$5 \& \#1\_a \textasciitilde{} \textasciicircum{}`,
+		`\auml{}hnlich \{x\}`:    `\"{a}hnlich \textbackslash{}\{x\textbackslash{}\}`,
+		`wait... \_  two spaces`: `wait\ldots{} \hspace*{1.0em}two spaces`,
+	} {
+		if actual := latexReplacer.Replace(input); actual != expected {
+			t.Errorf("latexReplacer.Replace(%q): got %q, expected %q", input, actual, expected)
+		}
+	}
+}
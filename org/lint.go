@@ -0,0 +1,174 @@
+package org
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// knownExportOptions contains the keys of the export options of Org mode and the non-standard options of go-org.
+// See https://orgmode.org/manual/Export-Settings.html.
+var knownExportOptions = map[string]bool{
+	"'": true, "*": true, "-": true, ":": true, "<": true, `\n`: true, "^": true, "|": true,
+	"arch": true, "author": true, "broken-links": true, "c": true, "creator": true, "d": true, "date": true,
+	"e": true, "email": true, "f": true, "H": true, "inline": true, "num": true, "p": true, "pri": true,
+	"prop": true, "stat": true, "tags": true, "tasks": true, "tex": true, "timestamp": true, "title": true,
+	"toc": true, "todo": true, "ealb": true,
+}
+
+// exportBackendOptionRegexp matches the keys of the export options of export backends (e.g. html-postamble of ox-html).
+// They are not checked as the options depend on the installed backends.
+var exportBackendOptionRegexp = regexp.MustCompile(`^(html5?|latex|md|ascii|odt|texinfo|beamer|man|icalendar)-`)
+
+// invalidTimestampRegexp matches text that looks like a timestamp. As valid timestamps are parsed as Timestamp
+// nodes, matches in (non raw) Text nodes are invalid timestamps - e.g. <2020-02-30 Sun> or <2020-01-01 Wed].
+var invalidTimestampRegexp = regexp.MustCompile(`[<\[]\d{4}-\d{2}-\d{2}[^<>\[\]\n]*[>\]]`)
+
+// Lint returns the diagnostics of the document (see Document.Diagnostics) and the problems found by checking
+// the document for common mistakes, sorted by position:
+// undefined footnotes, duplicate CUSTOM_IDs, internal links without target (see ResolveInternalLink),
+// id links to non-existent :ID: properties, property drawers that do not belong to a headline or the document,
+// invalid timestamps, unknown #+OPTIONS and unused #+LINK abbreviations.
+// Unclosed blocks are reported by the parser.
+// As id links usually point to other files, ids should contain the :ID: properties of all files the document
+// may link to (see Document.IDs). id links are only checked if ids is not nil.
+func (d *Document) Lint(ids map[string]bool) []Diagnostic {
+	diagnostics := append([]Diagnostic{}, d.Diagnostics...)
+	report := func(severity Severity, code string, span Span, message string) {
+		diagnostics = append(diagnostics, Diagnostic{severity, code, message, span})
+	}
+	footnotes, customIDs, usedLinks := map[string]bool{}, map[string]bool{}, map[string]bool{}
+	Inspect(func(n Node) bool {
+		switch n := n.(type) {
+		case FootnoteDefinition:
+			footnotes[n.Name] = true
+		case Headline:
+			if id, ok := n.Properties.Get("CUSTOM_ID"); ok && customIDs[id] {
+				report(SeverityError, CodeDuplicateCustomID, n.Properties.Span, "Duplicate CUSTOM_ID "+id)
+			} else if ok {
+				customIDs[id] = true
+			}
+		case RegularLink:
+			usedLinks[n.Protocol], usedLinks[n.URL] = true, true
+		}
+		return true
+	}, d.Nodes...)
+
+	localIDs := map[string]bool{}
+	for _, id := range d.IDs() {
+		localIDs[id] = true
+	}
+	for i, n := range d.Nodes {
+		if drawer, ok := n.(PropertyDrawer); ok && !isDocumentPropertyDrawer(d.Nodes[:i]) {
+			report(SeverityWarning, CodeMisplacedPropertyDrawer, drawer.Span, "Property drawer does not belong to a headline")
+		}
+	}
+	Inspect(func(n Node) bool {
+		switch n := n.(type) {
+		case Block, Example, LatexBlock, InlineBlock:
+			return false
+		case Headline:
+			for _, c := range n.Children {
+				if drawer, ok := c.(PropertyDrawer); ok {
+					report(SeverityWarning, CodeMisplacedPropertyDrawer, drawer.Span, "Property drawer is not directly below the headline")
+				}
+			}
+		case Drawer, ListItem, DescriptiveListItem, FootnoteDefinition:
+			for _, c := range children(n) {
+				if drawer, ok := c.(PropertyDrawer); ok {
+					report(SeverityWarning, CodeMisplacedPropertyDrawer, drawer.Span, "Property drawer does not belong to a headline")
+				}
+			}
+		case Keyword:
+			if n.Key == "OPTIONS" {
+				for _, field := range strings.Fields(n.Value) {
+					if key := strings.SplitN(field, ":", 2)[0]; !knownExportOptions[key] && !exportBackendOptionRegexp.MatchString(key) {
+						report(SeverityWarning, CodeUnknownExportOption, n.Span, "Unknown export option "+field)
+					}
+				}
+			} else if parts := strings.SplitN(n.Value, " ", 2); n.Key == "LINK" && !usedLinks[parts[0]] {
+				report(SeverityInfo, CodeUnusedLinkAbbreviation, n.Span, "Unused link abbreviation "+parts[0])
+			}
+		case Text:
+			if !n.IsRaw {
+				for _, m := range invalidTimestampRegexp.FindAllStringIndex(n.Content, -1) {
+					span := Span{d.textPosition(n, m[0]), d.textPosition(n, m[1])}
+					report(SeverityWarning, CodeInvalidTimestamp, span, "Invalid timestamp "+n.Content[m[0]:m[1]])
+				}
+			}
+		case FootnoteLink:
+			if n.Definition == nil && n.Name != "" && !footnotes[n.Name] {
+				report(SeverityWarning, CodeMissingFootnoteDefinition, n.Span, "Missing footnote definition for [fn:"+n.Name+"]")
+			}
+		case RegularLink:
+			if _, _, ok := d.ResolveInternalLink(n); ok {
+				break
+			} else if id := strings.TrimPrefix(n.URL, "id:"); n.Protocol == "id" && ids != nil && !ids[id] && !localIDs[id] {
+				report(SeverityWarning, CodeBrokenLink, n.Span, "Link to unknown ID "+n.URL)
+			} else if n.Protocol != "" {
+				break
+			} else if strings.HasPrefix(n.URL, "#") {
+				report(SeverityWarning, CodeBrokenLink, n.Span, "Link to unknown CUSTOM_ID "+n.URL)
+			} else if strings.HasPrefix(n.URL, "*") {
+				report(SeverityWarning, CodeBrokenLink, n.Span, "Link to unknown headline "+n.URL)
+			} else if d.Links[n.URL] == "" && !isFileLink(n.URL) {
+				report(SeverityWarning, CodeBrokenLink, n.Span, "Link to unknown target "+n.URL)
+			}
+		}
+		return true
+	}, d.Nodes...)
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].Span.Start.Offset < diagnostics[j].Span.Start.Offset
+	})
+	return diagnostics
+}
+
+// IDs returns the :ID: properties of the headlines of the document in document order.
+func (d *Document) IDs() []string {
+	ids := []string{}
+	Inspect(func(n Node) bool {
+		if h, ok := n.(Headline); ok {
+			if id, ok := h.Properties.Get("ID"); ok {
+				ids = append(ids, id)
+			}
+		}
+		return true
+	}, d.Nodes...)
+	return ids
+}
+
+// isFileLink returns true if the url of a link without protocol looks like a path (e.g. ./image.png or notes.org)
+// rather than the name of a target - such links are written as relative file links.
+func isFileLink(url string) bool {
+	return strings.Contains(url, "/") || path.Ext(strings.SplitN(url, "::", 2)[0]) != ""
+}
+
+// isDocumentPropertyDrawer returns true if a property drawer following nodes applies to the whole document,
+// i.e. if it is only preceded by keywords and comments.
+func isDocumentPropertyDrawer(nodes []Node) bool {
+	for _, n := range nodes {
+		switch n.(type) {
+		case Keyword, Comment:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// textPosition returns the Position of the byte index i of the content of the text t.
+// Indentation stripped from the lines of a paragraph is taken into account.
+func (d *Document) textPosition(t Text, i int) Position {
+	if !t.Span.IsValid() {
+		return Position{}
+	}
+	prefix := t.Content[:i]
+	lines := strings.Count(prefix, "\n")
+	if lines == 0 {
+		return d.Position(t.Span.Start.Offset + i)
+	} else if line := t.Span.Start.Line - 1 + lines; line < len(d.tokens) {
+		return d.Position(d.tokenStart(line) + len(prefix) - strings.LastIndex(prefix, "\n") - 1)
+	}
+	return Position{}
+}
@@ -0,0 +1,67 @@
+package org
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	input := strings.Join([]string{
+		"#+OPTIONS: toc:nil foo:t html-postamble:nil html5-fancy:t",
+		"#+LINK: gh https://github.com/%s",
+		"#+LINK: unused https://example.com/",
+		"* A",
+		":PROPERTIES:",
+		":CUSTOM_ID: a",
+		":END:",
+		"[[gh:niklasfasching]] [[#a]] [[#missing]] [[*A]] [[*Missing]] [fn:1] [fn:missing]",
+		"<<target>> [[target]] [[missing-name]] [[id:none]] [[id:b]] [[id:c]] [[A]] [[file.org]]",
+		"* B",
+		":PROPERTIES:",
+		":CUSTOM_ID: a",
+		":ID: b",
+		":END:",
+		"text",
+		":PROPERTIES:",
+		":FOO: bar",
+		":END:",
+		"  <2020-02-30 Sun> and",
+		"  [2020-01-01 Wed>",
+		"#+BEGIN_SRC go",
+		"",
+		"[fn:1] definition",
+	}, "\n")
+	d := New().Silent().Parse(strings.NewReader(input), "./test.org")
+	expected := []string{
+		"1:1: warning: Unknown export option foo:t [unknown-export-option]",
+		"3:1: info: Unused link abbreviation unused [unused-link-abbreviation]",
+		"8:30: warning: Link to unknown CUSTOM_ID #missing [broken-link]",
+		"8:50: warning: Link to unknown headline *Missing [broken-link]",
+		"8:70: warning: Missing footnote definition for [fn:missing] [missing-footnote-definition]",
+		"9:23: warning: Link to unknown target missing-name [broken-link]",
+		"9:40: warning: Link to unknown ID id:none [broken-link]",
+		"11:1: error: Duplicate CUSTOM_ID a [duplicate-custom-id]",
+		"16:1: warning: Property drawer is not directly below the headline [misplaced-property-drawer]",
+		"19:3: warning: Invalid timestamp <2020-02-30 Sun> [invalid-timestamp]",
+		"20:3: warning: Invalid timestamp [2020-01-01 Wed> [invalid-timestamp]",
+		"21:1: error: Missing #+END_SRC for #+BEGIN_SRC: Falling back to treating it as plain text. [unclosed-block]",
+	}
+	diagnostics := d.Lint(map[string]bool{"c": true})
+	actual := []string{}
+	for _, diagnostic := range diagnostics {
+		actual = append(actual, diagnostic.String())
+	}
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("got\n%s\n\nexpected\n%s", strings.Join(actual, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestLintWithoutIDs(t *testing.T) {
+	d := New().Silent().Parse(strings.NewReader("* A\n[[id:other-file][link]]"), "./test.org")
+	if diagnostics := d.Lint(nil); len(diagnostics) != 0 {
+		t.Errorf("expected id links not to be checked without ids, got %v", diagnostics)
+	}
+	if diagnostics := d.Lint(map[string]bool{}); len(diagnostics) != 1 || diagnostics[0].Code != CodeBrokenLink {
+		t.Errorf("expected a broken link diagnostic, got %v", diagnostics)
+	}
+}
@@ -10,6 +10,7 @@ import (
 type List struct {
 	Kind  string
 	Items []Node
+	Span  Span
 }
 
 type ListItem struct {
@@ -17,6 +18,7 @@ type ListItem struct {
 	Status   string
 	Value    string
 	Children []Node
+	Span     Span
 }
 
 type DescriptiveListItem struct {
@@ -24,6 +26,7 @@ type DescriptiveListItem struct {
 	Status  string
 	Term    []Node
 	Details []Node
+	Span    Span
 }
 
 var unorderedListRegexp = regexp.MustCompile(`^(\s*)([+*-])(\s+(.*)|$)`)
@@ -62,7 +65,7 @@ func listKind(t token) (string, string) {
 }
 
 func (d *Document) parseList(i int, parentStop stopFn) (int, Node) {
-	start, lvl := i, d.tokens[i].lvl
+	start, lvl, startOffset := i, d.tokens[i].lvl, d.tokenStart(i)
 	listMainKind, kind := listKind(d.tokens[i])
 	list := List{Kind: kind}
 	stop := func(*Document, int) bool {
@@ -77,11 +80,13 @@ func (d *Document) parseList(i int, parentStop stopFn) (int, Node) {
 		i += consumed
 		list.Items = append(list.Items, node)
 	}
+	list.Span = d.tokenSpan(start, i)
+	list.Span.Start = d.Position(startOffset)
 	return i - start, list
 }
 
 func (d *Document) parseListItem(l List, i int, parentStop stopFn) (int, Node) {
-	start, nodes, bullet := i, []Node{}, d.tokens[i].matches[2]
+	start, nodes, bullet, startOffset := i, []Node{}, d.tokens[i].matches[2], d.tokenStart(i)
 	minIndent, dterm, content, status, value := d.tokens[i].lvl+len(bullet), "", d.tokens[i].content, "", ""
 	originalBaseLvl := d.baseLvl
 	d.baseLvl = minIndent + 1
@@ -91,6 +96,7 @@ func (d *Document) parseListItem(l List, i int, parentStop stopFn) (int, Node) {
 	if m := listItemStatusRegexp.FindStringSubmatch(content); m != nil {
 		status, content = m[1], content[len("[ ] "):]
 	}
+	at := d.lineOffsets(i, content)
 	if l.Kind == "descriptive" {
 		if m := descriptiveListItemRegexp.FindStringIndex(content); m != nil {
 			dterm, content = content[:m[0]], content[m[1]:]
@@ -98,7 +104,7 @@ func (d *Document) parseListItem(l List, i int, parentStop stopFn) (int, Node) {
 		}
 	}
 
-	d.tokens[i] = tokenize(strings.Repeat(" ", minIndent) + content)
+	d.retokenize(i, content, minIndent)
 	stop := func(d *Document, i int) bool {
 		if parentStop(d, i) {
 			return true
@@ -112,10 +118,12 @@ func (d *Document) parseListItem(l List, i int, parentStop stopFn) (int, Node) {
 		nodes = append(nodes, node)
 	}
 	d.baseLvl = originalBaseLvl
+	span := d.tokenSpan(start, i)
+	span.Start = d.Position(startOffset)
 	if l.Kind == "descriptive" {
-		return i - start, DescriptiveListItem{bullet, status, d.parseInline(dterm), nodes}
+		return i - start, DescriptiveListItem{bullet, status, d.parseInline(dterm, at), nodes, span}
 	}
-	return i - start, ListItem{bullet, status, value, nodes}
+	return i - start, ListItem{bullet, status, value, nodes, span}
 }
 
 func (n List) String() string                { return String(n) }
@@ -0,0 +1,123 @@
+package org
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// sourceWriter is implemented by writers that can write nodes as their original source (see Configuration.Lossless).
+type sourceWriter interface {
+	writeSource(n Node) bool
+}
+
+type sourceKey struct {
+	span Span
+	kind reflect.Type
+}
+
+// recordSourceNodes records the block level nodes of the document, so that OrgWriter can later
+// check whether a node is unchanged and can be written as its original source.
+func (d *Document) recordSourceNodes() {
+	d.sourceNodes = map[sourceKey]Node{}
+	record := func(n Node) {
+		if span := SpanOf(n); span.IsValid() && !isInlineNode(n) {
+			d.sourceNodes[sourceKey{span, reflect.TypeOf(n)}] = n
+		}
+	}
+	Inspect(func(n Node) bool {
+		record(n)
+		return !isInlineNode(n)
+	}, d.Nodes...)
+}
+
+// sourceNode returns the node parsed from the span of n if n is a block level node parsed from the input.
+func (d *Document) sourceNode(n Node) (Span, Node, bool) {
+	span := SpanOf(n)
+	if d == nil || d.sourceNodes == nil || !span.IsValid() || isInlineNode(n) {
+		return span, nil, false
+	}
+	original, ok := d.sourceNodes[sourceKey{span, reflect.TypeOf(n)}]
+	return span, original, ok
+}
+
+// writeSource writes unchanged block level nodes as their original source and changed block level nodes
+// (re-rendered by the OrgWriter) along with the original whitespace between nodes.
+// Of changed headlines, the headline line, planning and property drawer are written as their source
+// if only the children of the headline changed.
+func (w *OrgWriter) writeSource(n Node) bool {
+	if w.document == nil || w.document.sourceNodes == nil {
+		return false
+	} else if w.rendering {
+		w.rendering = false
+		return false
+	}
+	span, original, ok := w.document.sourceNode(n)
+	if !ok {
+		return false
+	}
+	start, end := w.sourceRange(span)
+	if end == -1 {
+		return false
+	}
+	w.writeSourceGap(start)
+	if reflect.DeepEqual(n, original) {
+		w.WriteString(w.document.source[start:end])
+	} else if h, ok := n.(Headline); ok && isUnchangedHeadline(h, original.(Headline)) {
+		bodyStart := end
+		if children := original.(Headline).Children; len(children) != 0 {
+			bodyStart, _ = w.sourceRange(SpanOf(children[0]))
+		}
+		w.WriteString(w.document.source[start:bodyStart])
+		w.offset = bodyStart
+		WriteNodes(w, h.Children...)
+	} else {
+		w.offset, w.rendering = -1, true
+		WriteNodes(w, n)
+	}
+	w.offset = end
+	return true
+}
+
+// writeSourceGap writes the whitespace between the last node that was written as its source and offset.
+func (w *OrgWriter) writeSourceGap(offset int) {
+	if w.offset == -1 || w.offset > offset {
+		return
+	}
+	if gap := w.document.source[w.offset:offset]; strings.TrimSpace(gap) == "" {
+		w.WriteString(gap)
+	}
+}
+
+// sourceRange returns the range of the source that is written for a node with the given span: Leading indentation
+// and the line terminator are included. end is -1 if the span does not end at the end of a line.
+func (w *OrgWriter) sourceRange(span Span) (start, end int) {
+	source := w.document.source
+	start, end = span.Start.Offset, span.End.Offset
+	if lineStart := strings.LastIndexByte(source[:start], '\n') + 1; strings.TrimSpace(source[lineStart:start]) == "" {
+		start = lineStart
+	}
+	lineEnd := strings.IndexByte(source[end:], '\n')
+	if lineEnd == -1 {
+		lineEnd = len(source) - end
+	} else {
+		lineEnd++
+	}
+	if strings.TrimRightFunc(source[end:end+lineEnd], unicode.IsSpace) != "" {
+		return start, -1
+	}
+	return start, end + lineEnd
+}
+
+func isUnchangedHeadline(h, original Headline) bool {
+	h.Children, h.Clocks, original.Children, original.Clocks = nil, nil, nil, nil
+	return reflect.DeepEqual(h, original)
+}
+
+func isInlineNode(n Node) bool {
+	switch n.(type) {
+	case Text, Emphasis, LatexFragment, StatisticToken, ExplicitLineBreak, LineBreak, RegularLink, Target, RadioTarget, Macro, Timestamp, FootnoteLink, InlineBlock:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package org
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLosslessRoundTrip(t *testing.T) {
+	for _, path := range orgTestFiles() {
+		input := fileString(t, path)
+		c := New().Silent()
+		c.Lossless = true
+		actual, err := c.Parse(strings.NewReader(input), path).Write(NewOrgWriter())
+		if err != nil {
+			t.Errorf("%s: %s", path, err)
+		} else if actual != input {
+			t.Errorf("%s:\n%s", path, diff(actual, input))
+		}
+	}
+}
+
+func TestLosslessEdits(t *testing.T) {
+	input := `#+title:   weird   casing
+* TODO  first headline     :tag:
+  some   text
+   +  item one
+   +  item two
+
+#+begin_src go
+fmt.Println("hello")
+#+end_src
+* second    headline
+| a  |   b |
+|----+-----|
+| c | d |
+last paragraph`
+	c := New().Silent()
+	c.Lossless = true
+	d := c.Parse(strings.NewReader(input), "")
+	d.Nodes = Transform(d.Nodes, func(n Node) []Node {
+		switch n := n.(type) {
+		case Headline:
+			if n.Lvl == 1 && strings.Contains(String(n.Title...), "second") {
+				n.Title = []Node{Text{Content: "renamed"}}
+			}
+			return []Node{n}
+		case ListItem:
+			if strings.Contains(String(n.Children...), "two") {
+				return nil
+			}
+		case Block:
+			return []Node{n, Paragraph{Children: []Node{Text{Content: "inserted"}}}}
+		}
+		return []Node{n}
+	})
+	actual, err := d.Write(NewOrgWriter())
+	expected := `#+title:   weird   casing
+* TODO  first headline     :tag:
+  some   text
+   +  item one
+
+#+begin_src go
+fmt.Println("hello")
+#+end_src
+inserted
+* renamed
+| a  |   b |
+|----+-----|
+| c | d |
+last paragraph`
+	if err != nil {
+		t.Fatal(err)
+	} else if actual != expected {
+		t.Errorf("\n%s", diff(actual, expected))
+	}
+}
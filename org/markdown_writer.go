@@ -0,0 +1,743 @@
+package org
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	u "net/url"
+
+	h "golang.org/x/net/html"
+)
+
+// MarkdownWriter exports an org document into a GitHub flavored Markdown document.
+// Things that Markdown cannot express (e.g. underlines, sub- and superscripts, targets and videos)
+// are written as raw HTML.
+type MarkdownWriter struct {
+	ExtendingWriter Writer
+	// TopLevelHLevel determines what Markdown heading to use for a level-1 Org headline (see HTMLWriter).
+	// Headlines below level 6 are written as level 6 headings.
+	TopLevelHLevel int
+	// Diagnostics contains the problems found while writing (e.g. bad macros). They are not added to Document.Diagnostics.
+	Diagnostics []Diagnostic
+
+	strings.Builder
+	document  *Document
+	escape    bool   // escape is unset while writing the content of code blocks and code spans.
+	tightList bool   // tightList is set while writing a list that directly follows the first paragraph of a list item.
+	lastList  string // lastList is the kind of list bullets of the previous block if it is a list.
+	footnotes *footnotes
+}
+
+var emphasisMarkdownBorders = map[string][]string{
+	"/":   {"*", "*"},
+	"*":   {"**", "**"},
+	"+":   {"~~", "~~"},
+	"_":   {"<u>", "</u>"},
+	"_{}": {"<sub>", "</sub>"},
+	"^{}": {"<sup>", "</sup>"},
+}
+
+var markdownTaskListStatuses = map[string]string{
+	" ": "[ ]",
+	"-": "[ ]",
+	"X": "[x]",
+}
+
+var markdownLatexDelimiters = map[string]string{`\(`: "$", `\)`: "$", `\[`: "$$", `\]`: "$$"}
+
+var markdownEscapeRegexp = regexp.MustCompile("[\\\\`*\\[\\]<]")
+var backtickRunRegexp = regexp.MustCompile("`+")
+
+func NewMarkdownWriter() *MarkdownWriter {
+	return &MarkdownWriter{
+		document:       &Document{Configuration: New()},
+		escape:         true,
+		TopLevelHLevel: 2,
+		footnotes: &footnotes{
+			mapping: map[string]int{},
+			unused:  map[string]*FootnoteDefinition{},
+		},
+	}
+}
+
+func (w *MarkdownWriter) WriterWithExtensions() Writer {
+	if w.ExtendingWriter != nil {
+		return w.ExtendingWriter
+	}
+	return w
+}
+
+func (w *MarkdownWriter) WriteNodesAsString(nodes ...Node) string {
+	original := w.Builder
+	w.Builder = strings.Builder{}
+	WriteNodes(w, nodes...)
+	out := w.String()
+	w.Builder = original
+	return out
+}
+
+func (w *MarkdownWriter) Before(d *Document) {
+	w.document = d
+	if title := d.Get("TITLE"); title != "" && w.document.GetOption("title") != "nil" {
+		titleDocument := d.Parse(strings.NewReader(title), d.Path)
+		if titleDocument.Error == nil {
+			title = strings.TrimSpace(w.writeInline(titleDocument.Nodes))
+		}
+		w.WriteString("# " + title + "\n")
+	}
+	if w.document.GetOption("toc") != "nil" {
+		maxLvl, _ := strconv.Atoi(w.document.GetOption("toc"))
+		w.WriteOutline(d, maxLvl)
+	}
+}
+
+func (w *MarkdownWriter) After(d *Document) {
+	w.WriteFootnotes(d)
+}
+
+func (w *MarkdownWriter) WriteComment(Comment)               {}
+func (w *MarkdownWriter) WritePropertyDrawer(PropertyDrawer) {}
+
+func (w *MarkdownWriter) WriteKeyword(k Keyword) {
+	switch k.Key {
+	case "HTML", "MARKDOWN":
+		w.startBlock()
+		w.WriteString(k.Value + "\n")
+	case "TOC":
+		if m := tocHeadlineMaxLvlRegexp.FindStringSubmatch(k.Value); m != nil {
+			maxLvl, _ := strconv.Atoi(m[1])
+			w.WriteOutline(w.document, maxLvl)
+		}
+	}
+}
+
+func (w *MarkdownWriter) WriteInclude(i Include) {
+	WriteNodes(w, i.Resolve())
+}
+
+func (w *MarkdownWriter) WriteOutline(d *Document, maxLvl int) {
+	if len(d.Outline.Children) != 0 {
+		w.startBlock()
+		for _, section := range d.Outline.Children {
+			w.writeSection(section, maxLvl, "")
+		}
+	}
+}
+
+func (w *MarkdownWriter) writeSection(section *Section, maxLvl int, indent string) {
+	if (maxLvl != 0 && section.Headline.Lvl > maxLvl) || section.Headline.IsExcluded(w.document) {
+		return
+	}
+	h := section.Headline
+	title := Transform(h.Title, func(n Node) []Node {
+		// nested links are not valid Markdown
+		if l, ok := n.(RegularLink); ok {
+			if l.Description == nil {
+				return []Node{Text{Content: l.URL}}
+			}
+			return l.Description
+		}
+		return []Node{n}
+	})
+	w.WriteString(fmt.Sprintf("%s- [%s](#%s)\n", indent, strings.TrimSpace(w.WriteNodesAsString(title...)), h.ID()))
+	for _, section := range section.Children {
+		w.writeSection(section, maxLvl, indent+"  ")
+	}
+}
+
+func (w *MarkdownWriter) WriteHeadline(h Headline) {
+	if h.IsExcluded(w.document) {
+		w.footnotes.collectDefinitions(h.Children)
+		return
+	}
+	level := h.Lvl - 1 + w.TopLevelHLevel
+	if level > 6 {
+		level = 6
+	}
+	w.startBlock()
+	w.WriteString(fmt.Sprintf(`%s <a id="%s"></a>`, strings.Repeat("#", level), h.ID()))
+	if w.document.GetOption("todo") != "nil" && h.Status != "" {
+		w.WriteString(h.Status + " ")
+	}
+	if w.document.GetOption("pri") != "nil" && h.Priority != "" {
+		w.WriteString(`\[#` + h.Priority + `\] `)
+	}
+	w.WriteString(strings.TrimSpace(w.WriteNodesAsString(h.Title...)))
+	if w.document.GetOption("tags") != "nil" && len(h.Tags) != 0 {
+		w.WriteString(" " + escapeMarkdown(":"+strings.Join(h.Tags, ":")+":"))
+	}
+	w.WriteString("\n")
+	if entries := h.Planning(); w.document.GetOption("p") != "nil" && len(entries) != 0 {
+		planning := make([]string, len(entries))
+		for i, p := range entries {
+			planning[i] = fmt.Sprintf("**%s:** %s", p.Keyword, escapeMarkdown(String(*p.Timestamp)))
+		}
+		w.startBlock()
+		w.WriteString(strings.Join(planning, " ") + "\n")
+	}
+	WriteNodes(w, h.Children...)
+}
+
+func (w *MarkdownWriter) WriteBlock(b Block) {
+	content, params := w.blockContent(b.Name, b.Children), b.ParameterMap()
+	switch b.Name {
+	case "SRC":
+		if params[":exports"] == "results" || params[":exports"] == "none" {
+			break
+		}
+		if params[":noweb"] == "strip-export" {
+			content = regexp.MustCompile(`<<[^>]+>>`).ReplaceAllString(content, "")
+		}
+		lang := ""
+		if len(b.Parameters) >= 1 {
+			lang = strings.ToLower(b.Parameters[0])
+		}
+		w.writeCodeBlock(content, lang)
+	case "EXAMPLE":
+		w.writeCodeBlock(content, "")
+	case "EXPORT":
+		if len(b.Parameters) >= 1 && isMarkdownExportBackend(b.Parameters[0]) {
+			w.startBlock()
+			w.WriteString(content + "\n")
+		}
+	case "QUOTE":
+		w.startBlock()
+		w.WriteString(prefixLines(strings.TrimRight(content, "\n"), "> ") + "\n")
+	case "CENTER":
+		w.writeHTMLBlock(`<div align="center">`, content, "</div>")
+	case "VERSE":
+		lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+		for i := 0; i < len(lines)-1; i++ {
+			if strings.TrimSpace(lines[i]) != "" && strings.TrimSpace(lines[i+1]) != "" {
+				lines[i] += "\\"
+			}
+		}
+		w.writeHTMLBlock(`<div class="verse-block">`, strings.Join(lines, "\n"), "</div>")
+	default:
+		w.writeHTMLBlock(fmt.Sprintf(`<div class="%s-block">`, strings.ToLower(b.Name)), content, "</div>")
+	}
+	if b.Result != nil && params[":exports"] != "code" && params[":exports"] != "none" {
+		WriteNodes(w, b.Result)
+	}
+}
+
+func (w *MarkdownWriter) WriteResult(r Result) { WriteNodes(w, r.Node) }
+
+func (w *MarkdownWriter) WriteLatexBlock(b LatexBlock) {
+	w.startBlock()
+	w.WriteString("$$\n" + strings.TrimSpace(w.rawContent(b.Content)) + "\n$$\n")
+}
+
+func (w *MarkdownWriter) WriteInlineBlock(b InlineBlock) {
+	content := w.blockContent(strings.ToUpper(b.Name), b.Children)
+	switch b.Name {
+	case "src":
+		w.WriteString(codeSpan(content))
+	case "export":
+		if isMarkdownExportBackend(b.Parameters[0]) {
+			w.WriteString(content)
+		}
+	}
+}
+
+func (w *MarkdownWriter) WriteExample(e Example) {
+	lines := []string{}
+	for _, n := range e.Children {
+		lines = append(lines, w.rawContent([]Node{n}))
+	}
+	w.writeCodeBlock(strings.Join(lines, "\n"), "")
+}
+
+func (w *MarkdownWriter) WriteDrawer(d Drawer) {
+	WriteNodes(w, d.Children...)
+}
+
+func (w *MarkdownWriter) WriteClock(c Clock) {
+	if w.document.GetOption("c") == "nil" {
+		return
+	}
+	w.startBlock()
+	w.WriteString("**CLOCK:** " + escapeMarkdown(String(c.Timestamp)))
+	if c.Timestamp.End != nil {
+		w.WriteString(" (" + formatClockDuration(c.Duration) + ")")
+	}
+	w.WriteString("\n")
+}
+
+func (w *MarkdownWriter) WriteLogEntry(e LogEntry) { WriteNodes(w, e.Item) }
+
+func (w *MarkdownWriter) WriteFootnoteDefinition(f FootnoteDefinition) {
+	w.footnotes.updateDefinition(f)
+}
+
+// WriteFootnotes writes the footnote definitions of all footnotes referenced in the document - numbered
+// in order of their first reference like the footnotes of HTMLWriter.
+func (w *MarkdownWriter) WriteFootnotes(d *Document) {
+	if w.document.GetOption("f") == "nil" || len(w.footnotes.list) == 0 {
+		return
+	}
+	w.startBlock()
+	// iterate by index instead of ranging, since new footnotes can be added when writing the definitions
+	for i := 0; i < len(w.footnotes.list); i++ {
+		definition := w.footnotes.list[i]
+		if definition == nil {
+			continue
+		}
+		content := strings.TrimSpace(w.WriteNodesAsString(definition.Children...))
+		w.WriteString(hangingIndent(fmt.Sprintf("[^%d]: ", i+1), "    ", content) + "\n")
+	}
+}
+
+func (w *MarkdownWriter) WriteFootnoteLink(l FootnoteLink) {
+	if w.document.GetOption("f") == "nil" {
+		return
+	}
+	w.WriteString(fmt.Sprintf("[^%d]", w.footnotes.add(l)+1))
+}
+
+func (w *MarkdownWriter) WriteList(l List) {
+	kind := "unordered"
+	if l.Kind == "ordered" {
+		kind = "ordered"
+	}
+	lastList := w.lastList
+	if !w.tightList {
+		w.startBlock()
+	}
+	if lastList == kind {
+		// consecutive lists with the same kind of bullets are only separated by something other than a blank line
+		w.WriteString("<!-- -->\n\n")
+	}
+	w.tightList = false
+	WriteNodes(w, l.Items...)
+	w.lastList = kind
+}
+
+func (w *MarkdownWriter) WriteListItem(li ListItem) {
+	bullet := "-"
+	if m := orderedListBulletRegexp.FindStringSubmatch(li.Bullet); m != nil {
+		bullet = li.Bullet
+		if li.Value != "" {
+			bullet = li.Value + m[1]
+		}
+	}
+	prefix := bullet + " "
+	if li.Status != "" {
+		prefix += markdownTaskListStatuses[li.Status] + " "
+	}
+	w.writeListItem(prefix, strings.Repeat(" ", len(bullet)+1), li.Children)
+}
+
+// WriteDescriptiveListItem writes the item as regular list item starting with the term in bold - Markdown does
+// not have descriptive lists.
+func (w *MarkdownWriter) WriteDescriptiveListItem(di DescriptiveListItem) {
+	prefix := "- "
+	if di.Status != "" {
+		prefix += markdownTaskListStatuses[di.Status] + " "
+	}
+	if len(di.Term) != 0 {
+		prefix += "**" + strings.TrimSpace(w.WriteNodesAsString(di.Term...)) + "**: "
+	}
+	w.writeListItem(prefix, "  ", di.Details)
+}
+
+func (w *MarkdownWriter) writeListItem(prefix, indent string, children []Node) {
+	original := w.Builder
+	w.Builder = strings.Builder{}
+	for i, n := range children {
+		if _, isList := n.(List); isList && i != 0 {
+			_, w.tightList = children[i-1].(Paragraph)
+		}
+		WriteNodes(w, n)
+	}
+	content := strings.TrimRight(w.String(), "\n")
+	w.Builder, w.tightList = original, false
+	if content == "" {
+		w.WriteString(strings.TrimRight(prefix, " ") + "\n")
+		return
+	}
+	w.WriteString(hangingIndent(prefix, indent, content) + "\n")
+}
+
+func (w *MarkdownWriter) WriteTable(t Table) {
+	if len(t.ColumnInfos) == 0 {
+		return
+	}
+	rows := [][]string{}
+	inHead := len(t.SeparatorIndices) > 0 &&
+		t.SeparatorIndices[0] != len(t.Rows)-1 &&
+		(t.SeparatorIndices[0] != 0 || len(t.SeparatorIndices) > 1 && t.SeparatorIndices[len(t.SeparatorIndices)-1] != len(t.Rows)-1)
+	if !inHead {
+		rows = append(rows, make([]string, len(t.ColumnInfos)))
+	}
+	for _, row := range t.Rows {
+		if row.IsSpecial || len(row.Columns) == 0 {
+			continue
+		}
+		cells := make([]string, len(t.ColumnInfos))
+		for i, column := range row.Columns {
+			if i < len(cells) {
+				cells[i] = strings.ReplaceAll(strings.TrimSpace(w.WriteNodesAsString(column.Children...)), "|", `\|`)
+			}
+		}
+		rows = append(rows, cells)
+	}
+	w.startBlock()
+	for i, cells := range rows {
+		w.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+		if i == 0 {
+			separators := make([]string, len(t.ColumnInfos))
+			for j, info := range t.ColumnInfos {
+				switch info.Align {
+				case "left":
+					separators[j] = ":---"
+				case "center":
+					separators[j] = ":---:"
+				case "right":
+					separators[j] = "---:"
+				default:
+					separators[j] = "---"
+				}
+			}
+			w.WriteString("| " + strings.Join(separators, " | ") + " |\n")
+		}
+	}
+}
+
+func (w *MarkdownWriter) WriteHorizontalRule(HorizontalRule) {
+	w.startBlock()
+	w.WriteString("---\n")
+}
+
+func (w *MarkdownWriter) WriteParagraph(p Paragraph) {
+	content := strings.TrimRight(strings.TrimLeft(w.WriteNodesAsString(p.Children...), "\n"), " \n")
+	if content == "" {
+		return
+	}
+	// indented lines would be parsed as code blocks
+	lines := strings.Split(content, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimLeft(lines[i], " \t")
+	}
+	w.startBlock()
+	w.WriteString(strings.Join(lines, "\n") + "\n")
+}
+
+func (w *MarkdownWriter) WriteNodeWithMeta(n NodeWithMeta) {
+	if p, ok := n.Node.(Paragraph); ok && len(n.Meta.HTMLAttributes) != 0 && len(p.Children) == 1 && isImageOrVideoLink(p.Children[0]) {
+		// Markdown images cannot have attributes (e.g. width) - write them as img tag instead
+		l, attributes := p.Children[0].(RegularLink), []h.Attribute{}
+		for _, kvs := range n.Meta.HTMLAttributes {
+			for i := 0; i < len(kvs)-1; i += 2 {
+				attributes = setHTMLAttribute(attributes, strings.TrimPrefix(kvs[i], ":"), kvs[i+1])
+			}
+		}
+		tag := "img"
+		if l.Kind() == "video" {
+			tag = "video"
+		}
+		w.startBlock()
+		w.WriteString(fmt.Sprintf(`<%s src="%s"`, tag, html.EscapeString(w.url(l))))
+		for _, a := range attributes {
+			w.WriteString(fmt.Sprintf(` %s="%s"`, a.Key, html.EscapeString(a.Val)))
+		}
+		w.WriteString(">\n")
+	} else {
+		WriteNodes(w, n.Node)
+	}
+	if len(n.Meta.Caption) != 0 {
+		captions := []string{}
+		for _, ns := range n.Meta.Caption {
+			captions = append(captions, strings.TrimSpace(w.WriteNodesAsString(ns...)))
+		}
+		w.startBlock()
+		w.WriteString("*" + strings.Join(captions, " ") + "*\n")
+	}
+}
+
+func (w *MarkdownWriter) WriteNodeWithName(n NodeWithName) {
+	w.startBlock()
+	w.WriteString(fmt.Sprintf(`<a id="%s"></a>`, targetID(n.Name)) + "\n")
+	WriteNodes(w, n.Node)
+}
+
+func (w *MarkdownWriter) WriteText(t Text) {
+	if !w.escape {
+		w.WriteString(t.Content)
+	} else if w.document.GetOption("e") == "nil" || t.IsRaw {
+		w.WriteString(escapeMarkdown(t.Content))
+	} else {
+		w.WriteString(escapeMarkdown(htmlEntityReplacer.Replace(t.Content)))
+	}
+}
+
+func (w *MarkdownWriter) WriteEmphasis(e Emphasis) {
+	if e.Kind == "~" || e.Kind == "=" {
+		w.WriteString(codeSpan(w.rawContent(e.Content)))
+		return
+	}
+	borders, ok := emphasisMarkdownBorders[e.Kind]
+	if !ok {
+		panic(fmt.Sprintf("bad emphasis %#v", e))
+	}
+	w.WriteString(borders[0])
+	WriteNodes(w, e.Content...)
+	w.WriteString(borders[1])
+}
+
+func (w *MarkdownWriter) WriteLatexFragment(l LatexFragment) {
+	opening, closing := l.OpeningPair, l.ClosingPair
+	if delimiter, ok := markdownLatexDelimiters[opening]; ok {
+		opening = delimiter
+	}
+	if delimiter, ok := markdownLatexDelimiters[closing]; ok {
+		closing = delimiter
+	}
+	w.WriteString(opening + w.rawContent(l.Content) + closing)
+}
+
+func (w *MarkdownWriter) WriteStatisticToken(s StatisticToken) {
+	w.WriteString(codeSpan("[" + s.Content + "]"))
+}
+
+func (w *MarkdownWriter) WriteLineBreak(l LineBreak) {
+	if w.document.GetOption("ealb") == "nil" || !l.BetweenMultibyteCharacters {
+		w.WriteString(strings.Repeat("\n", l.Count))
+	}
+}
+
+func (w *MarkdownWriter) WriteExplicitLineBreak(ExplicitLineBreak) {
+	w.WriteString("\\\n")
+}
+
+func (w *MarkdownWriter) WriteTimestamp(t Timestamp) {
+	if w.document.GetOption("<") != "nil" {
+		w.WriteString(escapeMarkdown(String(t)))
+	}
+}
+
+func (w *MarkdownWriter) WriteRegularLink(l RegularLink) {
+	if id, title, ok := w.document.ResolveInternalLink(l); ok {
+		description := l.Description
+		if description == nil {
+			description = title
+		}
+		w.WriteString(fmt.Sprintf("[%s](#%s)", w.WriteNodesAsString(description...), id))
+		return
+	} else if l.AutoLink {
+		w.WriteString(l.URL)
+		return
+	}
+	url := w.url(l)
+	switch l.Kind() {
+	case "image":
+		if l.Description == nil {
+			w.WriteString(fmt.Sprintf("![%s](%s)", escapeMarkdown(url), markdownLinkDestination(url)))
+		} else {
+			description := strings.TrimPrefix(String(l.Description...), "file:")
+			w.WriteString(fmt.Sprintf("[![%s](%s)](%s)", escapeMarkdown(description), markdownLinkDestination(description), markdownLinkDestination(url)))
+		}
+	case "video":
+		if l.Description == nil {
+			w.WriteString(fmt.Sprintf(`<video src="%s" title="%s">%s</video>`, url, url, url))
+		} else {
+			description := strings.TrimPrefix(String(l.Description...), "file:")
+			w.WriteString(fmt.Sprintf(`<a href="%s"><video src="%s" title="%s"></video></a>`, url, description, description))
+		}
+	default:
+		if isURL := strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://"); isURL && l.Description == nil && !strings.ContainsAny(url, " <>") {
+			w.WriteString("<" + url + ">")
+			return
+		}
+		description := escapeMarkdown(url)
+		if l.Description != nil {
+			description = w.WriteNodesAsString(l.Description...)
+		}
+		w.WriteString(fmt.Sprintf("[%s](%s)", description, markdownLinkDestination(url)))
+	}
+}
+
+// url returns the url of the link - links to .org files are turned into links to the exported .md files.
+func (w *MarkdownWriter) url(l RegularLink) string {
+	url, anchor := l.URL, ""
+	if l.Protocol == "file" {
+		url = url[len("file:"):]
+	}
+	if isRelative := l.Protocol == "file" || l.Protocol == ""; isRelative {
+		if i := strings.Index(url, "::"); i != -1 {
+			if strings.HasPrefix(url[i+2:], "#") {
+				anchor = url[i+2:]
+			}
+			url = url[:i]
+		}
+		if strings.HasSuffix(url, ".org") {
+			url = strings.TrimSuffix(url, ".org") + ".md"
+		}
+	}
+	if prefix := w.document.Links[l.Protocol]; prefix != "" {
+		if tag := strings.TrimPrefix(l.URL, l.Protocol+":"); strings.Contains(prefix, "%s") || strings.Contains(prefix, "%h") {
+			url = strings.ReplaceAll(strings.ReplaceAll(prefix, "%s", tag), "%h", u.QueryEscape(tag))
+		} else {
+			url = prefix + tag
+		}
+	} else if prefix := w.document.Links[l.URL]; prefix != "" {
+		url = strings.ReplaceAll(strings.ReplaceAll(prefix, "%s", ""), "%h", "")
+	}
+	return url + anchor
+}
+
+func (w *MarkdownWriter) WriteTarget(t Target) {
+	w.WriteString(fmt.Sprintf(`<a id="%s"></a>`, targetID(t.Name)))
+}
+
+func (w *MarkdownWriter) WriteRadioTarget(t RadioTarget) {
+	w.WriteString(fmt.Sprintf(`<a id="%s"></a>%s`, targetID(t.Name), escapeMarkdown(t.Name)))
+}
+
+func (w *MarkdownWriter) WriteMacro(m Macro) {
+	if macro := w.document.Macros[m.Name]; macro != "" {
+		for i, param := range m.Parameters {
+			macro = strings.Replace(macro, fmt.Sprintf("$%d", i+1), param, -1)
+		}
+		macroDocument := w.document.Parse(strings.NewReader(macro), w.document.Path)
+		if macroDocument.Error != nil {
+			w.Diagnostics = append(w.Diagnostics, w.document.diagnostic(SeverityWarning, CodeBadMacro, m.Span, "bad macro: %s -> %s: %v", m.Name, macro, macroDocument.Error))
+		}
+		w.WriteString(strings.TrimSpace(w.writeInline(macroDocument.Nodes)))
+	}
+}
+
+// writeInline returns nodes written as inline Markdown - a single paragraph is written as its children.
+func (w *MarkdownWriter) writeInline(nodes []Node) string {
+	if len(nodes) == 1 {
+		if p, ok := nodes[0].(Paragraph); ok {
+			return w.WriteNodesAsString(p.Children...)
+		}
+	}
+	return w.WriteNodesAsString(nodes...)
+}
+
+// startBlock separates the block that is written next from the previous one by a blank line.
+func (w *MarkdownWriter) startBlock() {
+	w.lastList = ""
+	if out := w.String(); out != "" && !strings.HasSuffix(out, "\n\n") {
+		w.WriteString("\n")
+	}
+}
+
+func (w *MarkdownWriter) writeCodeBlock(content, lang string) {
+	fence := "```"
+	for _, run := range backtickRunRegexp.FindAllString(content, -1) {
+		if len(run) >= len(fence) {
+			fence = strings.Repeat("`", len(run)+1)
+		}
+	}
+	w.startBlock()
+	w.WriteString(fence + lang + "\n" + content + "\n" + fence + "\n")
+}
+
+// writeHTMLBlock writes content wrapped in the html tags. The blank lines around the content
+// make Markdown parse the content as Markdown rather than raw HTML.
+func (w *MarkdownWriter) writeHTMLBlock(open, content, close string) {
+	w.startBlock()
+	w.WriteString(open + "\n\n" + strings.TrimRight(content, "\n") + "\n\n" + close + "\n")
+}
+
+// rawContent returns nodes written without escaping Markdown (e.g. for code spans and LaTeX).
+func (w *MarkdownWriter) rawContent(nodes []Node) string {
+	escape := w.escape
+	w.escape = false
+	out := w.WriteNodesAsString(nodes...)
+	w.escape = escape
+	return out
+}
+
+func (w *MarkdownWriter) blockContent(name string, children []Node) string {
+	if isRawTextBlock(name) {
+		return strings.TrimRightFunc(strings.TrimLeftFunc(w.rawContent(children), IsNewLineChar), unicode.IsSpace)
+	}
+	original, tightList := w.Builder, w.tightList
+	w.Builder, w.tightList = strings.Builder{}, false
+	WriteNodes(w, children...)
+	out := w.String()
+	w.Builder, w.tightList = original, tightList
+	return out
+}
+
+func isMarkdownExportBackend(backend string) bool {
+	switch strings.ToLower(backend) {
+	case "md", "markdown", "gfm", "html":
+		return true
+	}
+	return false
+}
+
+// escapeMarkdown escapes the characters of s that would otherwise start Markdown inline markup.
+// Underscores are only escaped at word boundaries - GitHub flavored Markdown ignores them inside of words.
+func escapeMarkdown(s string) string {
+	s = markdownEscapeRegexp.ReplaceAllString(s, `\$0`)
+	if !strings.Contains(s, "_") {
+		return s
+	}
+	b := strings.Builder{}
+	for i, r := range s {
+		if r == '_' {
+			before, _ := utf8.DecodeLastRuneInString(s[:i])
+			after, _ := utf8.DecodeRuneInString(s[i+1:])
+			if !isWordRune(before) || !isWordRune(after) {
+				b.WriteRune('\\')
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// codeSpan returns s as Markdown code span - delimited by more backticks than the longest run of backticks in s.
+func codeSpan(s string) string {
+	delimiter := "`"
+	for _, run := range backtickRunRegexp.FindAllString(s, -1) {
+		if len(run) >= len(delimiter) {
+			delimiter = strings.Repeat("`", len(run)+1)
+		}
+	}
+	if strings.HasPrefix(s, "`") || strings.HasSuffix(s, "`") {
+		s = " " + s + " "
+	}
+	return delimiter + strings.ReplaceAll(s, "\n", " ") + delimiter
+}
+
+// markdownLinkDestination returns url as link destination - urls containing spaces or parentheses are wrapped in <>.
+func markdownLinkDestination(url string) string {
+	if strings.ContainsAny(url, " ()<>") {
+		return "<" + strings.NewReplacer("<", `\<`, ">", `\>`).Replace(url) + ">"
+	}
+	return url
+}
+
+// prefixLines returns s with prefix added to all lines. Blank lines are only prefixed with the non-space part of prefix.
+func prefixLines(s, prefix string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = strings.TrimRight(prefix, " ")
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// hangingIndent returns the first line of s after prefix and all other lines indented by indent (e.g. for list items).
+func hangingIndent(prefix, indent, s string) string {
+	lines := strings.SplitN(s, "\n", 2)
+	if len(lines) == 1 {
+		return prefix + lines[0]
+	}
+	return prefix + lines[0] + "\n" + prefixLines(lines[1], indent)
+}
@@ -0,0 +1,19 @@
+package org
+
+import "testing"
+
+func TestMarkdownWriter(t *testing.T) {
+	testWriter(t, func() Writer { return NewMarkdownWriter() }, ".md")
+}
+
+func TestEscapeMarkdown(t *testing.T) {
+	for input, expected := range map[string]string{
+		"snake_case and _underscores_": `snake_case and \_underscores\_`,
+		"*stars* and [brackets]":       `\*stars\* and \[brackets\]`,
+		"<tag> `code` \\":              "\\<tag> \\`code\\` \\\\",
+	} {
+		if actual := escapeMarkdown(input); actual != expected {
+			t.Errorf("escapeMarkdown(%q): got %q, expected %q", input, actual, expected)
+		}
+	}
+}
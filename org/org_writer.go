@@ -14,7 +14,10 @@ type OrgWriter struct {
 	TagsColumn      int
 
 	strings.Builder
-	indent string
+	indent    string
+	document  *Document // document is set for documents parsed with Configuration.Lossless - see writeSource.
+	offset    int       // offset is the end of the source written last.
+	rendering bool      // rendering is set while a changed node is re-rendered by writeSource.
 }
 
 var exampleBlockUnescapeRegexp = regexp.MustCompile(`(^|\n)([ \t]*)(\*|,\*|#\+|,#\+)`)
@@ -43,8 +46,18 @@ func (w *OrgWriter) WriterWithExtensions() Writer {
 	return w
 }
 
-func (w *OrgWriter) Before(d *Document) {}
-func (w *OrgWriter) After(d *Document)  {}
+func (w *OrgWriter) Before(d *Document) {
+	if d.sourceNodes != nil {
+		w.document, w.offset = d, 0
+	}
+}
+
+func (w *OrgWriter) After(d *Document) {
+	if w.document != nil {
+		w.writeSourceGap(len(w.document.source))
+		w.document = nil
+	}
+}
 
 func (w *OrgWriter) WriteNodesAsString(nodes ...Node) string {
 	builder := w.Builder
@@ -64,6 +77,9 @@ func (w *OrgWriter) WriteHeadline(h Headline) {
 	if h.Priority != "" {
 		w.WriteString(" [#" + h.Priority + "]")
 	}
+	if h.IsComment {
+		w.WriteString(" COMMENT")
+	}
 	w.WriteString(" ")
 	WriteNodes(w, h.Title...)
 	if len(h.Tags) != 0 {
@@ -75,6 +91,13 @@ func (w *OrgWriter) WriteHeadline(h Headline) {
 		}
 	}
 	w.WriteString("\n")
+	if entries := h.Planning(); len(entries) != 0 {
+		planning := make([]string, len(entries))
+		for i, p := range entries {
+			planning[i] = p.Keyword + ": " + w.WriteNodesAsString(*p.Timestamp)
+		}
+		w.WriteString(strings.Join(planning, " ") + "\n")
+	}
 	if len(h.Children) != 0 {
 		w.WriteString(w.indent)
 	}
@@ -143,6 +166,16 @@ func (w *OrgWriter) WriteDrawer(d Drawer) {
 	w.WriteString(w.indent + ":END:\n")
 }
 
+func (w *OrgWriter) WriteClock(c Clock) {
+	w.WriteString(w.indent + "CLOCK: " + w.WriteNodesAsString(c.Timestamp))
+	if c.Timestamp.End != nil {
+		w.WriteString(fmt.Sprintf(" => %5s", formatClockDuration(c.Duration)))
+	}
+	w.WriteString("\n")
+}
+
+func (w *OrgWriter) WriteLogEntry(e LogEntry) { WriteNodes(w, e.Item) }
+
 func (w *OrgWriter) WritePropertyDrawer(d PropertyDrawer) {
 	w.WriteString(":PROPERTIES:\n")
 	for _, kvPair := range d.Properties {
@@ -204,6 +237,10 @@ func (w *OrgWriter) WriteNodeWithMeta(n NodeWithMeta) {
 		w.WriteString("#+ATTR_HTML: ")
 		w.WriteString(strings.Join(attributes, " ") + "\n")
 	}
+	for _, attributes := range n.Meta.LaTeXAttributes {
+		w.WriteString("#+ATTR_LATEX: ")
+		w.WriteString(strings.Join(attributes, " ") + "\n")
+	}
 	WriteNodes(w, n.Node)
 }
 
@@ -339,16 +376,34 @@ func (w *OrgWriter) WriteExplicitLineBreak(l ExplicitLineBreak) {
 }
 
 func (w *OrgWriter) WriteTimestamp(t Timestamp) {
-	w.WriteString("<")
+	if t.Diary != "" {
+		w.WriteString("<%%" + t.Diary + ">")
+		return
+	}
+	open, close := "<", ">"
+	if t.IsInactive {
+		open, close = "[", "]"
+	}
+	w.WriteString(open)
 	if t.IsDate {
 		w.WriteString(t.Time.Format(datestampFormat))
 	} else {
 		w.WriteString(t.Time.Format(timestampFormat))
 	}
+	if t.IsTimeRange && t.End != nil {
+		w.WriteString("-" + t.End.Time.Format("15:04"))
+	}
 	if t.Interval != "" {
 		w.WriteString(" " + t.Interval)
 	}
-	w.WriteString(">")
+	if t.Warning != "" {
+		w.WriteString(" " + t.Warning)
+	}
+	w.WriteString(close)
+	if !t.IsTimeRange && t.End != nil {
+		w.WriteString("--")
+		w.WriteTimestamp(*t.End)
+	}
 }
 
 func (w *OrgWriter) WriteFootnoteLink(l FootnoteLink) {
@@ -361,7 +416,9 @@ func (w *OrgWriter) WriteFootnoteLink(l FootnoteLink) {
 }
 
 func (w *OrgWriter) WriteRegularLink(l RegularLink) {
-	if l.AutoLink {
+	if l.Protocol == "radio" {
+		WriteNodes(w, l.Description...)
+	} else if l.AutoLink {
 		w.WriteString(l.URL)
 	} else if l.Description == nil {
 		w.WriteString(fmt.Sprintf("[[%s]]", l.URL))
@@ -370,6 +427,10 @@ func (w *OrgWriter) WriteRegularLink(l RegularLink) {
 	}
 }
 
+func (w *OrgWriter) WriteTarget(t Target) { w.WriteString("<<" + t.Name + ">>") }
+
+func (w *OrgWriter) WriteRadioTarget(t RadioTarget) { w.WriteString("<<<" + t.Name + ">>>") }
+
 func (w *OrgWriter) WriteMacro(m Macro) {
 	w.WriteString(fmt.Sprintf("{{{%s(%s)}}}", m.Name, strings.Join(m.Parameters, ",")))
 }
@@ -6,8 +6,12 @@ import (
 	"strings"
 )
 
-type Paragraph struct{ Children []Node }
-type HorizontalRule struct{}
+type Paragraph struct {
+	Children []Node
+	Span     Span
+}
+
+type HorizontalRule struct{ Span Span }
 
 var horizontalRuleRegexp = regexp.MustCompile(`^(\s*)-{5,}\s*$`)
 var plainTextRegexp = regexp.MustCompile(`^(\s*)(.*)`)
@@ -27,20 +31,21 @@ func lexHorizontalRule(line string) (token, bool) {
 }
 
 func (d *Document) parseParagraph(i int, parentStop stopFn) (int, Node) {
-	lines, start := []string{d.tokens[i].content}, i
+	lines, bases, start := []string{d.tokens[i].content}, []int{d.tokenStart(i)}, i
 	stop := func(d *Document, i int) bool {
 		return parentStop(d, i) || d.tokens[i].kind != "text" || d.tokens[i].content == ""
 	}
 	for i += 1; !stop(d, i); i++ {
 		lvl := math.Max(float64(d.tokens[i].lvl-d.baseLvl), 0)
 		lines = append(lines, strings.Repeat(" ", int(lvl))+d.tokens[i].content)
+		bases = append(bases, d.tokenStart(i)-int(lvl))
 	}
 	consumed := i - start
-	return consumed, Paragraph{d.parseInline(strings.Join(lines, "\n"))}
+	return consumed, Paragraph{d.parseInline(strings.Join(lines, "\n"), joinedOffsets(lines, bases)), d.tokenSpan(start, i)}
 }
 
 func (d *Document) parseHorizontalRule(i int, parentStop stopFn) (int, Node) {
-	return 1, HorizontalRule{}
+	return 1, HorizontalRule{d.tokenSpan(i, i+1)}
 }
 
 func (n Paragraph) String() string      { return String(n) }
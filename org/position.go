@@ -0,0 +1,294 @@
+package org
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Position is a location in the parse input.
+type Position struct {
+	Offset int // Offset is the byte offset, starting at 0.
+	Line   int // Line is the line number, starting at 1.
+	Column int // Column is the byte offset in the line, starting at 1.
+}
+
+// Span is the part of the parse input a node was parsed from. End is exclusive.
+// Nodes that were not parsed from the input (e.g. created programmatically) have a zero Span.
+type Span struct {
+	Start Position
+	End   Position
+}
+
+// offsetFn maps byte indices of a string passed to parseInline to byte offsets in the parse input.
+// A nil offsetFn means the string does not originate from the parse input.
+type offsetFn func(int) int
+
+func (p Position) IsValid() bool   { return p.Line > 0 }
+func (p Position) String() string  { return fmt.Sprintf("%d:%d", p.Line, p.Column) }
+func (s Span) IsValid() bool       { return s.Start.IsValid() }
+func (s Span) String() string      { return fmt.Sprintf("%s-%s", s.Start, s.End) }
+func (s Span) Contains(o int) bool { return s.IsValid() && s.Start.Offset <= o && o < s.End.Offset }
+
+// SpanOf returns the Span of the parse input n was parsed from.
+func SpanOf(n Node) Span {
+	switch n := n.(type) {
+	case Keyword:
+		return n.Span
+	case Include:
+		return n.Span
+	case Comment:
+		return n.Span
+	case NodeWithMeta:
+		return n.Span
+	case NodeWithName:
+		return n.Span
+	case Headline:
+		return n.Span
+	case Block:
+		return n.Span
+	case Result:
+		return n.Span
+	case LatexBlock:
+		return n.Span
+	case InlineBlock:
+		return n.Span
+	case Example:
+		return n.Span
+	case Drawer:
+		return n.Span
+	case PropertyDrawer:
+		return n.Span
+	case List:
+		return n.Span
+	case ListItem:
+		return n.Span
+	case DescriptiveListItem:
+		return n.Span
+	case Table:
+		return n.Span
+	case HorizontalRule:
+		return n.Span
+	case Paragraph:
+		return n.Span
+	case Text:
+		return n.Span
+	case Emphasis:
+		return n.Span
+	case LatexFragment:
+		return n.Span
+	case StatisticToken:
+		return n.Span
+	case ExplicitLineBreak:
+		return n.Span
+	case LineBreak:
+		return n.Span
+	case RegularLink:
+		return n.Span
+	case Target:
+		return n.Span
+	case RadioTarget:
+		return n.Span
+	case Macro:
+		return n.Span
+	case Timestamp:
+		return n.Span
+	case FootnoteLink:
+		return n.Span
+	case FootnoteDefinition:
+		return n.Span
+	case Clock:
+		return n.Span
+	case LogEntry:
+		return n.Span
+	}
+	return Span{}
+}
+
+// setSpan returns n with its Span set to s. Nodes of unknown types are returned unchanged.
+func setSpan(n Node, s Span) Node {
+	switch n := n.(type) {
+	case Keyword:
+		n.Span = s
+		return n
+	case Include:
+		n.Span = s
+		return n
+	case Comment:
+		n.Span = s
+		return n
+	case NodeWithMeta:
+		n.Span = s
+		return n
+	case NodeWithName:
+		n.Span = s
+		return n
+	case Headline:
+		n.Span = s
+		return n
+	case Block:
+		n.Span = s
+		return n
+	case Result:
+		n.Span = s
+		return n
+	case LatexBlock:
+		n.Span = s
+		return n
+	case InlineBlock:
+		n.Span = s
+		return n
+	case Example:
+		n.Span = s
+		return n
+	case Drawer:
+		n.Span = s
+		return n
+	case PropertyDrawer:
+		n.Span = s
+		return n
+	case List:
+		n.Span = s
+		return n
+	case ListItem:
+		n.Span = s
+		return n
+	case DescriptiveListItem:
+		n.Span = s
+		return n
+	case Table:
+		n.Span = s
+		return n
+	case HorizontalRule:
+		n.Span = s
+		return n
+	case Paragraph:
+		n.Span = s
+		return n
+	case Text:
+		n.Span = s
+		return n
+	case Emphasis:
+		n.Span = s
+		return n
+	case LatexFragment:
+		n.Span = s
+		return n
+	case StatisticToken:
+		n.Span = s
+		return n
+	case ExplicitLineBreak:
+		n.Span = s
+		return n
+	case LineBreak:
+		n.Span = s
+		return n
+	case RegularLink:
+		n.Span = s
+		return n
+	case Target:
+		n.Span = s
+		return n
+	case RadioTarget:
+		n.Span = s
+		return n
+	case Macro:
+		n.Span = s
+		return n
+	case Timestamp:
+		n.Span = s
+		return n
+	case FootnoteLink:
+		n.Span = s
+		return n
+	case FootnoteDefinition:
+		n.Span = s
+		return n
+	case Clock:
+		n.Span = s
+		return n
+	case LogEntry:
+		n.Span = s
+		return n
+	}
+	return n
+}
+
+// Position returns the Position of the given byte offset in the parse input.
+func (d *Document) Position(offset int) Position {
+	if len(d.lines) == 0 {
+		return Position{offset, 1, offset + 1}
+	}
+	i := sort.Search(len(d.lines), func(i int) bool { return d.lines[i][0] > offset }) - 1
+	if i < 0 {
+		i = 0
+	}
+	return Position{offset, i + 1, offset - d.lines[i][0] + 1}
+}
+
+// tokenSpan returns the Span of the tokens [start, end) - excluding trailing blank lines.
+func (d *Document) tokenSpan(start, end int) Span {
+	if start >= end || end > len(d.tokens) {
+		return Span{}
+	}
+	for end-1 > start && d.tokens[end-1].kind == "text" && d.tokens[end-1].content == "" {
+		end--
+	}
+	return Span{d.Position(d.tokenStart(start)), d.Position(d.lines[end-1][1])}
+}
+
+// tokenStart returns the offset of the first non whitespace character of token i.
+func (d *Document) tokenStart(i int) int { return d.offsets[i] + d.tokens[i].lvl }
+
+// retokenize replaces token i with the token of suffix (a suffix of its line) indented by indent spaces.
+func (d *Document) retokenize(i int, suffix string, indent int) {
+	d.offsets[i] = d.lines[i][1] - len(suffix) - indent
+	d.tokens[i] = tokenize(strings.Repeat(" ", indent) + suffix)
+}
+
+// trimmedLine returns the line of token i trimmed by trim and its offset in the parse input.
+func (d *Document) trimmedLine(i int, trim func(string) string) (string, int) {
+	line := trim(d.tokens[i].matches[0])
+	return line, d.offsets[i] + len(d.tokens[i].matches[0]) - len(line)
+}
+
+func (d *Document) inlineSpan(at offsetFn, start, end int) Span {
+	if at == nil {
+		return Span{}
+	}
+	return Span{d.Position(at(start)), d.Position(at(end))}
+}
+
+// lineOffsets returns the offsetFn for s, which must be a (right trimmed) suffix of the line of token i.
+func (d *Document) lineOffsets(i int, s string) offsetFn {
+	base := d.offsets[i] + strings.LastIndex(d.tokens[i].matches[0], s)
+	return func(j int) int { return base + j }
+}
+
+// joinedOffsets returns the offsetFn for the string created by joining lines with "\n",
+// where bases[i] is the offset of lines[i][0] in the parse input.
+func joinedOffsets(lines []string, bases []int) offsetFn {
+	starts := make([]int, len(lines))
+	for i := 1; i < len(lines); i++ {
+		starts[i] = starts[i-1] + len(lines[i-1]) + 1
+	}
+	return func(j int) int {
+		i := sort.SearchInts(starts, j+1) - 1
+		return bases[i] + j - starts[i]
+	}
+}
+
+// skip returns the offsetFn for the string created by removing one byte before each of the (ascending) indices.
+func (at offsetFn) skip(indices []int) offsetFn {
+	if at == nil || len(indices) == 0 {
+		return at
+	}
+	return func(j int) int { return at(j + sort.SearchInts(indices, j+1)) }
+}
+
+func (at offsetFn) shift(n int) offsetFn {
+	if at == nil {
+		return nil
+	}
+	return func(j int) int { return at(j + n) }
+}
@@ -0,0 +1,52 @@
+package org
+
+import (
+	"strings"
+	"testing"
+)
+
+var spanTestInput = `#+TITLE: spans
+* TODO [#A] A *headline* :tag:
+Some /emphasis/ and a [[https://example.com][*link*]]
+  on two lines.
+- [ ] item
+  - nested
+| a | *b* |
+[fn:1] definition
+`
+
+func TestSpans(t *testing.T) {
+	d := New().Silent().Parse(strings.NewReader(spanTestInput), "./spans.org")
+	headline := d.Nodes[1].(Headline)
+	paragraph := headline.Children[0].(Paragraph)
+	list := headline.Children[1].(List)
+	table := headline.Children[2].(Table)
+	nodes := map[string]Node{
+		"#+TITLE: spans":                  d.Nodes[0],
+		"*headline*":                      headline.Title[1],
+		"headline":                        headline.Title[1].(Emphasis).Content[0],
+		"/emphasis/":                      paragraph.Children[1],
+		"*link*":                          paragraph.Children[3].(RegularLink).Description[0],
+		"- [ ] item\n  - nested":          list.Items[0],
+		"- nested":                        list.Items[0].(ListItem).Children[1],
+		"*b*":                             table.Rows[0].Columns[1].Children[0],
+		"[fn:1] definition":               headline.Children[3],
+		"[[https://example.com][*link*]]": paragraph.Children[3],
+		"Some /emphasis/ and a [[https://example.com][*link*]]\n  on two lines.": paragraph,
+	}
+	for expected, n := range nodes {
+		span := SpanOf(n)
+		if actual := spanTestInput[span.Start.Offset:span.End.Offset]; actual != expected {
+			t.Errorf("%T %s: got %q, expected %q", n, span, actual, expected)
+		}
+	}
+	if span := SpanOf(table); span.Start.Line != 7 || span.Start.Column != 1 || span.End.Line != 7 {
+		t.Errorf("bad table span: %s", span)
+	}
+	if span := SpanOf(paragraph.Children[5]); span.Start.Line != 4 || span.Start.Column != 1 || span.End.Column != 16 {
+		t.Errorf("bad span of text on second line: %s", span)
+	}
+	if span := SpanOf(headline); span.Start.Line != 2 || span.End.Line != 8 {
+		t.Errorf("bad headline span: %s", span)
+	}
+}
@@ -0,0 +1,79 @@
+package org
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Priorities is the range of headline priorities as defined by #+PRIORITIES: highest lowest default.
+// Priorities are either uppercase letters (e.g. A C B) or numbers (e.g. 1 5 3).
+type Priorities struct {
+	Highest string
+	Lowest  string
+	Default string
+}
+
+var headlinePriorityRegexp = regexp.MustCompile(`^\[#([A-Z]|[0-9]+)\]`)
+var defaultPriorities = Priorities{"A", "C", "B"}
+
+// Priorities returns the priority range of the document. Invalid #+PRIORITIES settings are ignored.
+func (d *Document) Priorities() Priorities {
+	fields := strings.Fields(d.Get("PRIORITIES"))
+	if len(fields) != 3 {
+		return defaultPriorities
+	}
+	p := Priorities{fields[0], fields[1], fields[2]}
+	highest, ok1 := priorityValue(p.Highest)
+	lowest, ok2 := priorityValue(p.Lowest)
+	if !ok1 || !ok2 || highest > lowest || isNumericPriority(p.Highest) != isNumericPriority(p.Lowest) || !p.IsValid(p.Default) {
+		return defaultPriorities
+	}
+	return p
+}
+
+// IsValid returns true if priority is in the range [Highest, Lowest].
+func (p Priorities) IsValid(priority string) bool {
+	value, ok := priorityValue(priority)
+	highest, _ := priorityValue(p.Highest)
+	lowest, _ := priorityValue(p.Lowest)
+	return ok && isNumericPriority(priority) == isNumericPriority(p.Highest) && highest <= value && value <= lowest
+}
+
+// Compare returns a negative number if priority a is higher than b, a positive number if it is lower and 0 otherwise.
+// Empty priorities are treated as the default priority.
+func (p Priorities) Compare(a, b string) int {
+	return p.rank(a) - p.rank(b)
+}
+
+// rank returns the distance of priority from the highest priority.
+func (p Priorities) rank(priority string) int {
+	if priority == "" {
+		priority = p.Default
+	}
+	value, _ := priorityValue(priority)
+	highest, _ := priorityValue(p.Highest)
+	return value - highest
+}
+
+// EffectivePriority returns the priority of the headline or the default priority of the document if it has none.
+func (h Headline) EffectivePriority(d *Document) string {
+	if h.Priority != "" {
+		return h.Priority
+	}
+	return d.Priorities().Default
+}
+
+func priorityValue(priority string) (int, bool) {
+	if isNumericPriority(priority) {
+		value, err := strconv.Atoi(priority)
+		return value, err == nil
+	} else if len(priority) == 1 && priority[0] >= 'A' && priority[0] <= 'Z' {
+		return int(priority[0]), true
+	}
+	return 0, false
+}
+
+func isNumericPriority(priority string) bool {
+	return priority != "" && priority[0] >= '0' && priority[0] <= '9'
+}
@@ -0,0 +1,41 @@
+package org
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPriorities(t *testing.T) {
+	d := New().Silent().Parse(strings.NewReader("#+PRIORITIES: 1 10 5\n* [#10] low\n* default\n"), "")
+	p := d.Priorities()
+	if p != (Priorities{"1", "10", "5"}) {
+		t.Errorf("unexpected priorities: %v", p)
+	}
+	if p.Compare("2", "10") >= 0 || p.Compare("", "5") != 0 || p.Compare("7", "") <= 0 {
+		t.Errorf("unexpected priority comparison")
+	}
+	if p.IsValid("0") || p.IsValid("11") || p.IsValid("A") || !p.IsValid("10") {
+		t.Errorf("unexpected priority validation")
+	}
+	if h := d.Nodes[1].(Headline); h.Priority != "10" || h.EffectivePriority(d) != "10" {
+		t.Errorf("unexpected priority: %#v", h.Priority)
+	}
+	if h := d.Nodes[2].(Headline); h.Priority != "" || h.EffectivePriority(d) != "5" {
+		t.Errorf("unexpected default priority: %#v", h.EffectivePriority(d))
+	}
+	titles := []string{}
+	for _, item := range TodoList([]*Document{New().Silent().Parse(strings.NewReader("#+PRIORITIES: A C B\n* TODO [#A] a\n* TODO [#B] b\n* TODO default\n"), "")}, AgendaFilter{Priorities: []string{"B"}}) {
+		titles = append(titles, String(item.Headline.Title...))
+	}
+	if strings.Join(titles, ",") != "b,default" {
+		t.Errorf("expected headlines without priority to match the default priority: %v", titles)
+	}
+	late := New().Silent().Parse(strings.NewReader("* [#7] headline\n#+PRIORITIES: 1 10 5\n"), "")
+	if h := late.Nodes[0].(Headline); h.Priority != "7" {
+		t.Errorf("expected #+PRIORITIES after the first headline to apply: %#v", h.Priority)
+	}
+	invalid := New().Silent().Parse(strings.NewReader("#+PRIORITIES: C A B\n"), "")
+	if p := invalid.Priorities(); p != defaultPriorities {
+		t.Errorf("expected invalid priorities to be ignored: %v", p)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
 	"unicode/utf8"
 )
 
@@ -11,6 +12,7 @@ type Table struct {
 	Rows             []Row
 	ColumnInfos      []ColumnInfo
 	SeparatorIndices []int
+	Span             Span
 }
 
 type Row struct {
@@ -44,30 +46,38 @@ func lexTable(line string) (token, bool) {
 }
 
 func (d *Document) parseTable(i int, parentStop stopFn) (int, Node) {
-	rawRows, separatorIndices, start := [][]string{}, []int{}, i
+	rawRows, rawOffsets, separatorIndices, start := [][]string{}, [][]int{}, []int{}, i
 	for ; !parentStop(d, i); i++ {
 		if t := d.tokens[i]; t.kind == "tableRow" {
-			rawRow := strings.FieldsFunc(d.tokens[i].content, func(r rune) bool { return r == '|' })
-			for i := range rawRow {
-				rawRow[i] = strings.TrimSpace(rawRow[i])
+			rawRow, offsets, base := []string{}, []int{}, d.tokenStart(i)
+			for j, field := 0, ""; j < len(t.content); j += len(field) + 1 {
+				if field = t.content[j:]; strings.IndexByte(field, '|') != -1 {
+					field = field[:strings.IndexByte(field, '|')]
+				}
+				if field != "" {
+					trimmed := strings.TrimSpace(field)
+					rawRow = append(rawRow, trimmed)
+					offsets = append(offsets, base+j+len(field)-len(strings.TrimLeftFunc(field, unicode.IsSpace)))
+				}
 			}
-			rawRows = append(rawRows, rawRow)
+			rawRows, rawOffsets = append(rawRows, rawRow), append(rawOffsets, offsets)
 		} else if t.kind == "tableSeparator" {
 			separatorIndices = append(separatorIndices, i-start)
-			rawRows = append(rawRows, nil)
+			rawRows, rawOffsets = append(rawRows, nil), append(rawOffsets, nil)
 		} else {
 			break
 		}
 	}
 
-	table := Table{nil, getColumnInfos(rawRows), separatorIndices}
-	for _, rawColumns := range rawRows {
+	table := Table{nil, getColumnInfos(rawRows), separatorIndices, d.tokenSpan(start, i)}
+	for j, rawColumns := range rawRows {
 		row := Row{nil, isSpecialRow(rawColumns)}
 		if len(rawColumns) != 0 {
 			for i := range table.ColumnInfos {
 				column := Column{nil, &table.ColumnInfos[i]}
 				if i < len(rawColumns) {
-					column.Children = d.parseInline(rawColumns[i])
+					base := rawOffsets[j][i]
+					column.Children = d.parseInline(rawColumns[i], func(k int) int { return base + k })
 				}
 				row.Columns = append(row.Columns, column)
 			}
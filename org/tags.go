@@ -0,0 +1,94 @@
+package org
+
+import (
+	"strings"
+)
+
+// TagGroup is a group of tags defined by #+TAGS, e.g. { @home @work } or [ Project : a b ].
+type TagGroup struct {
+	Name        string // Name is the group tag (e.g. Project). It is empty for groups without one.
+	Tags        []string
+	IsExclusive bool // IsExclusive is true for groups of mutually exclusive tags, i.e. groups in braces.
+}
+
+// TagDefinitions contains the tags and tag groups defined by #+TAGS.
+type TagDefinitions struct {
+	Tags   []string
+	Groups []TagGroup
+}
+
+// FileTags returns the tags defined by #+FILETAGS. They are inherited by all headlines of the document.
+func (d *Document) FileTags() []string {
+	return uniqueStrings(strings.FieldsFunc(d.Get("FILETAGS"), func(r rune) bool { return r == ':' || r == ' ' || r == '\t' || r == '\n' }))
+}
+
+// TagDefinitions returns the tags and tag groups defined by all #+TAGS lines of the document.
+func (d *Document) TagDefinitions() TagDefinitions {
+	ds := TagDefinitions{}
+	for _, line := range strings.Split(d.Get("TAGS"), "\n") {
+		var group *TagGroup
+		for _, field := range strings.Fields(line) {
+			switch field {
+			case "{", "[":
+				group = &TagGroup{IsExclusive: field == "{"}
+			case "}", "]":
+				if group != nil {
+					ds.Groups = append(ds.Groups, *group)
+				}
+				group = nil
+			case ":":
+				if group != nil && group.Name == "" && len(group.Tags) == 1 {
+					group.Name, group.Tags = group.Tags[0], nil
+				}
+			case `\n`:
+			default:
+				tag := trimFastTags([]string{field})[0]
+				if group != nil {
+					group.Tags = append(group.Tags, tag)
+				}
+				if !containsString(ds.Tags, tag) {
+					ds.Tags = append(ds.Tags, tag)
+				}
+			}
+		}
+	}
+	return ds
+}
+
+// Expand returns tags and the names of all (transitively) enclosing groups of tags.
+func (ds TagDefinitions) Expand(tags []string) []string {
+	expanded := append([]string{}, tags...)
+	for i := 0; i < len(expanded); i++ {
+		for _, g := range ds.Groups {
+			if g.Name != "" && !containsString(expanded, g.Name) && containsString(g.Tags, expanded[i]) {
+				expanded = append(expanded, g.Name)
+			}
+		}
+	}
+	return expanded
+}
+
+// EffectiveTags returns the inherited tags and the tags of the headline.
+func (h Headline) EffectiveTags() []string {
+	return uniqueStrings(append(append([]string{}, h.InheritedTags...), h.Tags...))
+}
+
+// inheritedTags returns the tags inherited by a headline of level lvl that is parsed next, i.e. the #+FILETAGS
+// and the tags of its ancestors - except for the Configuration.TagsExcludeFromInheritance.
+func (d *Document) inheritedTags(lvl int) []string {
+	parent := d.Outline.last
+	for parent.Headline != nil && parent.Headline.Lvl >= lvl {
+		parent = parent.Parent
+	}
+	tags := d.FileTags()
+	if parent.Headline != nil {
+		tags = parent.Headline.EffectiveTags()
+	}
+	inherited := []string{}
+	for _, tag := range tags {
+		if !containsString(d.TagsExcludeFromInheritance, tag) {
+			inherited = append(inherited, tag)
+		}
+	}
+	return inherited
+}
@@ -0,0 +1,62 @@
+package org
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestTags(t *testing.T) {
+	f, err := os.Open("./testdata/tags.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	c := New().Silent()
+	c.TagsExcludeFromInheritance = []string{"urgent"}
+	d := c.Parse(f, "./testdata/tags.org")
+	expected := "{[@home @work Chores laundry dishes urgent] [{ [@home @work] true} {Chores [laundry dishes] false}]}"
+	if actual := fmt.Sprint(d.TagDefinitions()); actual != expected {
+		t.Errorf("unexpected tag definitions:\n%s", diff(actual, expected))
+	}
+	if actual := fmt.Sprint(d.TagDefinitions().Expand([]string{"dishes"})); actual != "[dishes Chores]" {
+		t.Errorf("unexpected expanded tags: %s", actual)
+	}
+	parent := d.Nodes[3].(Headline)
+	child := parent.Children[0].(Headline)
+	if actual := fmt.Sprint(parent.EffectiveTags(), child.EffectiveTags()); actual != "[project urgent] [project]" {
+		t.Errorf("unexpected effective tags: %s", actual)
+	}
+	private := d.Nodes[4].(Headline)
+	if !private.Children[0].(Headline).IsExcluded(d) || !d.Nodes[5].(Headline).IsExcluded(d) || d.Nodes[6].(Headline).IsExcluded(d) {
+		t.Errorf("unexpected exclusion")
+	}
+}
+
+func TestFileTagsAfterFirstHeadline(t *testing.T) {
+	d := New().Silent().Parse(strings.NewReader(`* headline :own:
+#+BEGIN_SRC org
+#+FILETAGS: :ignored:
+#+END_SRC
+#+FILETAGS: :late:
+`), "./test.org")
+	if actual := fmt.Sprint(d.Nodes[0].(Headline).EffectiveTags()); actual != "[late own]" {
+		t.Errorf("unexpected effective tags: %s", actual)
+	}
+}
+
+func TestSelectTagsAfterFirstHeadline(t *testing.T) {
+	d := New().Silent().Parse(strings.NewReader(`* selected :pick:
+* not selected
+* excluded :pick:skip:
+#+SELECT_TAGS: pick
+#+EXCLUDE_TAGS: skip
+`), "./test.org")
+	if d.Nodes[0].(Headline).IsExcluded(d) || !d.Nodes[1].(Headline).IsExcluded(d) || !d.Nodes[2].(Headline).IsExcluded(d) {
+		t.Errorf("expected #+SELECT_TAGS and #+EXCLUDE_TAGS after the first headline to apply")
+	}
+	if d.Outline.count != 2 {
+		t.Errorf("unexpected outline count: %d", d.Outline.count)
+	}
+}
@@ -0,0 +1,128 @@
+package org
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Target is a <<target>>. Links without protocol (e.g. [[target]]) link to the target with the same name.
+type Target struct {
+	Name string
+	Span Span
+}
+
+// RadioTarget is a <<<radio target>>>. All occurrences of its name in the text of the document are linked to it,
+// i.e. they are parsed as RegularLinks with the protocol radio.
+type RadioTarget struct {
+	Name string
+	Span Span
+}
+
+var targetRegexp = regexp.MustCompile(`^<<([^<>\s](?:[^<>\n]*[^<>\s])?)>>`)
+var radioTargetRegexp = regexp.MustCompile(`^<<<([^<>\s](?:[^<>\n]*[^<>\s])?)>>>`)
+var radioTargetsRegexp = regexp.MustCompile(strings.TrimPrefix(radioTargetRegexp.String(), "^"))
+
+func (d *Document) parseTarget(input string, start int) (int, Node) {
+	if m := radioTargetRegexp.FindStringSubmatch(input[start:]); m != nil {
+		return len(m[0]), RadioTarget{m[1], Span{}}
+	} else if m := targetRegexp.FindStringSubmatch(input[start:]); m != nil {
+		return len(m[0]), Target{m[1], Span{}}
+	}
+	return 0, nil
+}
+
+// parseRadioLink parses an occurrence of the name of a radio target (case insensitive, at word boundaries).
+func (d *Document) parseRadioLink(input string, start int, at offsetFn) (int, Node) {
+	if r, _ := utf8.DecodeLastRuneInString(input[:start]); start != 0 && isWordRune(r) {
+		return 0, nil
+	}
+	for _, name := range d.radioTargets {
+		end := start + len(name)
+		if end > len(input) || !strings.EqualFold(input[start:end], name) {
+			continue
+		} else if r, _ := utf8.DecodeRuneInString(input[end:]); end != len(input) && isWordRune(r) {
+			continue
+		}
+		description := []Node{Text{input[start:end], false, d.inlineSpan(at, start, end)}}
+		return end - start, RegularLink{"radio", description, "radio:" + name, false, Span{}}
+	}
+	return 0, nil
+}
+
+// collectRadioTargets collects the names of all radio targets of the document, so that parseInline can link
+// their occurrences - including the ones before the radio target.
+func (d *Document) collectRadioTargets() {
+	d.radioTargets = nil
+	for _, t := range d.tokens {
+		for _, m := range radioTargetsRegexp.FindAllStringSubmatch(t.matches[0], -1) {
+			if !containsString(d.radioTargets, m[1]) {
+				d.radioTargets = append(d.radioTargets, m[1])
+			}
+		}
+	}
+}
+
+// collectTargets collects the names of all targets and radio targets of the parsed nodes for ResolveInternalLink.
+func (d *Document) collectTargets() {
+	d.targets = map[string]bool{}
+	Inspect(func(n Node) bool {
+		switch n := n.(type) {
+		case Target:
+			d.targets[n.Name] = true
+		case RadioTarget:
+			d.targets[n.Name] = true
+		}
+		return true
+	}, d.Nodes...)
+}
+
+// ResolveInternalLink returns the id of the HTML element (see HTMLWriter) and the title of the target of an internal link:
+//   - #custom-id: the headline with the CUSTOM_ID
+//   - *title: the headline with the title
+//   - radio:name: the radio target with the name
+//   - name: the target or radio target with the name, the element with the #+NAME or the headline with the title
+//
+// ok is false if l is not an internal link or has no target (e.g. links to files like [[file.org]]).
+func (d *Document) ResolveInternalLink(l RegularLink) (id string, title []Node, ok bool) {
+	if l.Protocol == "radio" {
+		return targetID(strings.TrimPrefix(l.URL, "radio:")), []Node{Text{Content: strings.TrimPrefix(l.URL, "radio:")}}, true
+	} else if l.Protocol != "" {
+		return "", nil, false
+	}
+	var headline *Headline
+	var walk func([]*Section) bool
+	walk = func(sections []*Section) bool {
+		for _, s := range sections {
+			if h := s.Headline; strings.HasPrefix(l.URL, "#") && h.ID() == l.URL[1:] ||
+				!strings.HasPrefix(l.URL, "#") && strings.TrimSpace(String(h.Title...)) == strings.TrimPrefix(l.URL, "*") {
+				headline = h
+				return true
+			} else if walk(s.Children) {
+				return true
+			}
+		}
+		return false
+	}
+	if strings.HasPrefix(l.URL, "#") || strings.HasPrefix(l.URL, "*") {
+		if walk(d.Outline.Children) {
+			return headline.ID(), headline.Title, true
+		}
+		return "", nil, false
+	}
+	if _, named := d.NamedNodes[l.URL]; d.targets[l.URL] || named {
+		return targetID(l.URL), []Node{Text{Content: l.URL}}, true
+	} else if walk(d.Outline.Children) {
+		return headline.ID(), headline.Title, true
+	}
+	return "", nil, false
+}
+
+// targetID returns the id of the HTML element of a target or named element.
+func targetID(name string) string { return strings.Join(strings.Fields(name), "-") }
+
+func isWordRune(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+
+func (n Target) String() string      { return String(n) }
+func (n RadioTarget) String() string { return String(n) }
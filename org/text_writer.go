@@ -0,0 +1,799 @@
+package org
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	u "net/url"
+)
+
+// TextWriter exports an org document into wrapped plain text similar to ox-ascii (e.g. for terminals and emails).
+// Links with a description are written as references to a list of the link targets at the end of their section,
+// footnotes are collected at the end of the document.
+type TextWriter struct {
+	ExtendingWriter Writer
+	// Width is the maximum width of lines. Paragraphs are wrapped at Width - code blocks, tables and long words are not.
+	// Paragraphs are not wrapped if Width is 0 or negative.
+	Width int
+	// ASCII restricts the characters used for markup to ASCII (e.g. for tables, bullets and entities) - instead of UTF-8.
+	// The text of the document itself is not changed.
+	ASCII bool
+	// Diagnostics contains the problems found while writing (e.g. bad macros). They are not added to Document.Diagnostics.
+	Diagnostics []Diagnostic
+
+	strings.Builder
+	document       *Document
+	width          int      // width is the width available for the block that is written next (i.e. Width minus indentation).
+	tightList      bool     // tightList is set while writing a list that directly follows the first paragraph of a list item.
+	listDepth      int      // listDepth is the nesting depth of the list that is currently written.
+	keepLines      bool     // keepLines is set while writing content whose lines are kept as is (e.g. of verse and source blocks).
+	sectionNumbers []int    // sectionNumbers is the number of the last headline of each level (see OPTIONS num).
+	links          []string // links are the link references of the current section.
+	footnotes      *footnotes
+}
+
+// textCharset is the set of characters used to draw the structure of the document.
+type textCharset struct {
+	underlines []string // underlines are the characters used to underline headlines of level 1, 2, ...
+	bullets    []string // bullets are the bullets of unordered lists of depth 1, 2, ...
+	horizontal string
+	vertical   string
+	corners    [3][3]string // corners are the table borders of the top, middle and bottom row from left to right.
+	ellipsis   string
+}
+
+var utf8TextCharset = textCharset{
+	underlines: []string{"═", "─", "╌", "┄", "┈"},
+	bullets:    []string{"•", "◦", "▪"},
+	horizontal: "─",
+	vertical:   "│",
+	corners:    [3][3]string{{"┌", "┬", "┐"}, {"├", "┼", "┤"}, {"└", "┴", "┘"}},
+	ellipsis:   "…",
+}
+
+var asciiTextCharset = textCharset{
+	underlines: []string{"=", "~", "-"},
+	bullets:    []string{"-", "*", "+"},
+	horizontal: "-",
+	vertical:   "|",
+	corners:    [3][3]string{{"+", "+", "+"}, {"+", "+", "+"}, {"+", "+", "+"}},
+	ellipsis:   "...",
+}
+
+var emphasisTextBorders = map[string][]string{
+	"/":   {"/", "/"},
+	"*":   {"*", "*"},
+	"+":   {"+", "+"},
+	"_":   {"_", "_"},
+	"~":   {"`", "`"},
+	"=":   {"`", "`"},
+	"_{}": {"_{", "}"},
+	"^{}": {"^{", "}"},
+}
+
+var textListItemStatuses = map[string]string{
+	" ": "[ ]",
+	"-": "[-]",
+	"X": "[X]",
+}
+
+// defaultTextWidth is the default Width of a TextWriter - and the width of horizontal rules if paragraphs are not wrapped.
+const defaultTextWidth = 72
+
+func NewTextWriter() *TextWriter {
+	return &TextWriter{
+		document: &Document{Configuration: New()},
+		Width:    defaultTextWidth,
+		footnotes: &footnotes{
+			mapping: map[string]int{},
+			unused:  map[string]*FootnoteDefinition{},
+		},
+	}
+}
+
+func (w *TextWriter) WriterWithExtensions() Writer {
+	if w.ExtendingWriter != nil {
+		return w.ExtendingWriter
+	}
+	return w
+}
+
+func (w *TextWriter) WriteNodesAsString(nodes ...Node) string {
+	original := w.Builder
+	w.Builder = strings.Builder{}
+	WriteNodes(w, nodes...)
+	out := w.String()
+	w.Builder = original
+	return out
+}
+
+func (w *TextWriter) Before(d *Document) {
+	w.document, w.width = d, w.Width
+	if title := d.Get("TITLE"); title != "" && w.document.GetOption("title") != "nil" {
+		titleDocument := d.Parse(strings.NewReader(title), d.Path)
+		if titleDocument.Error == nil {
+			title = w.plainTitle(titleDocument.Nodes)
+		}
+		underline := strings.Repeat(w.charset().underlines[0], textWidth(title))
+		w.WriteString(centerLine(title, w.width) + "\n" + centerLine(underline, w.width) + "\n")
+	}
+	if w.document.GetOption("toc") != "nil" {
+		maxLvl, _ := strconv.Atoi(w.document.GetOption("toc"))
+		w.WriteOutline(d, maxLvl)
+	}
+}
+
+func (w *TextWriter) After(d *Document) {
+	w.writeLinks()
+	w.WriteFootnotes(d)
+	w.writeLinks()
+}
+
+func (w *TextWriter) WriteComment(Comment)               {}
+func (w *TextWriter) WritePropertyDrawer(PropertyDrawer) {}
+
+func (w *TextWriter) WriteKeyword(k Keyword) {
+	switch k.Key {
+	case "ASCII", "TEXT":
+		w.startBlock()
+		w.WriteString(k.Value + "\n")
+	case "TOC":
+		if m := tocHeadlineMaxLvlRegexp.FindStringSubmatch(k.Value); m != nil {
+			maxLvl, _ := strconv.Atoi(m[1])
+			w.WriteOutline(w.document, maxLvl)
+		}
+	}
+}
+
+func (w *TextWriter) WriteInclude(i Include) {
+	WriteNodes(w, i.Resolve())
+}
+
+func (w *TextWriter) WriteOutline(d *Document, maxLvl int) {
+	if len(d.Outline.Children) != 0 {
+		w.writeHeading("Table of Contents", 1)
+		w.startBlock()
+		numbers := []int{}
+		for _, section := range d.Outline.Children {
+			numbers = w.writeSection(section, maxLvl, "", numbers)
+		}
+	}
+}
+
+func (w *TextWriter) writeSection(section *Section, maxLvl int, indent string, numbers []int) []int {
+	h := section.Headline
+	if h.IsExcluded(w.document) {
+		return numbers
+	}
+	numbers, number := nextSectionNumber(numbers, h.Lvl)
+	if maxLvl != 0 && h.Lvl > maxLvl {
+		return numbers
+	}
+	prefix := w.charset().bullets[0] + " "
+	if w.document.GetOption("num") != "nil" {
+		prefix = number + " "
+	}
+	title := w.plainTitle(Transform(h.Title, func(n Node) []Node {
+		if _, ok := n.(FootnoteLink); ok {
+			return nil
+		}
+		return []Node{n}
+	}))
+	w.WriteString(strings.TrimRight(indent+prefix+title, " ") + "\n")
+	for _, section := range section.Children {
+		numbers = w.writeSection(section, maxLvl, indent+strings.Repeat(" ", textWidth(prefix)), numbers)
+	}
+	return numbers
+}
+
+func (w *TextWriter) WriteHeadline(h Headline) {
+	if h.IsExcluded(w.document) {
+		w.footnotes.collectDefinitions(h.Children)
+		return
+	}
+	// the links of the previous section are listed before the next headline
+	w.writeLinks()
+	numbers, number := nextSectionNumber(w.sectionNumbers, h.Lvl)
+	w.sectionNumbers = numbers
+	title := ""
+	if w.document.GetOption("num") != "nil" {
+		title += number + " "
+	}
+	if w.document.GetOption("todo") != "nil" && h.Status != "" {
+		title += h.Status + " "
+	}
+	if w.document.GetOption("pri") != "nil" && h.Priority != "" {
+		title += "[#" + h.Priority + "] "
+	}
+	title += w.plainTitle(h.Title)
+	if w.document.GetOption("tags") != "nil" && len(h.Tags) != 0 {
+		tags := ":" + strings.Join(h.Tags, ":") + ":"
+		if padding := w.width - textWidth(title) - textWidth(tags); padding > 0 {
+			title += strings.Repeat(" ", padding) + tags
+		} else {
+			title += " " + tags
+		}
+	}
+	w.writeHeading(title, h.Lvl)
+	if entries := h.Planning(); w.document.GetOption("p") != "nil" && len(entries) != 0 {
+		planning := make([]string, len(entries))
+		for i, p := range entries {
+			planning[i] = p.Keyword + ": " + String(*p.Timestamp)
+		}
+		w.startBlock()
+		w.WriteString(strings.Join(planning, " ") + "\n")
+	}
+	WriteNodes(w, h.Children...)
+}
+
+func (w *TextWriter) writeHeading(title string, lvl int) {
+	underlines := w.charset().underlines
+	underline := underlines[len(underlines)-1]
+	if lvl <= len(underlines) {
+		underline = underlines[lvl-1]
+	}
+	w.startBlock()
+	w.WriteString(title + "\n" + strings.Repeat(underline, textWidth(title)) + "\n")
+}
+
+// plainTitle returns the title written as a single line of text. Links are written as their description
+// as titles cannot reference the list of links of a section.
+func (w *TextWriter) plainTitle(nodes []Node) string {
+	if len(nodes) == 1 {
+		if p, ok := nodes[0].(Paragraph); ok {
+			nodes = p.Children
+		}
+	}
+	nodes = Transform(nodes, func(n Node) []Node {
+		if l, ok := n.(RegularLink); ok && l.Description != nil {
+			return l.Description
+		}
+		return []Node{n}
+	})
+	return strings.Join(strings.Fields(w.WriteNodesAsString(nodes...)), " ")
+}
+
+func (w *TextWriter) WriteBlock(b Block) {
+	content, params := w.blockContent(b.Name, b.Children), b.ParameterMap()
+	switch b.Name {
+	case "SRC":
+		if params[":exports"] == "results" || params[":exports"] == "none" {
+			break
+		}
+		if params[":noweb"] == "strip-export" {
+			content = regexp.MustCompile(`<<[^>]+>>`).ReplaceAllString(content, "")
+		}
+		w.writeIndented(content, "    ")
+	case "EXAMPLE", "VERSE":
+		w.writeIndented(content, "    ")
+	case "EXPORT":
+		if len(b.Parameters) >= 1 && isTextExportBackend(b.Parameters[0]) {
+			w.startBlock()
+			w.WriteString(content + "\n")
+		}
+	case "QUOTE":
+		w.writeIndented(content, "    ")
+	case "CENTER":
+		lines := strings.Split(strings.Trim(content, "\n"), "\n")
+		for i, line := range lines {
+			lines[i] = centerLine(line, w.width)
+		}
+		w.startBlock()
+		w.WriteString(strings.Join(lines, "\n") + "\n")
+	default:
+		w.startBlock()
+		w.WriteString(strings.Trim(content, "\n") + "\n")
+	}
+	if b.Result != nil && params[":exports"] != "code" && params[":exports"] != "none" {
+		WriteNodes(w, b.Result)
+	}
+}
+
+func (w *TextWriter) WriteResult(r Result) { WriteNodes(w, r.Node) }
+
+func (w *TextWriter) WriteLatexBlock(b LatexBlock) {
+	w.startBlock()
+	w.WriteString(strings.TrimSpace(w.linesContent(b.Content)) + "\n")
+}
+
+func (w *TextWriter) WriteInlineBlock(b InlineBlock) {
+	content := w.blockContent(strings.ToUpper(b.Name), b.Children)
+	switch b.Name {
+	case "src":
+		w.WriteString("`" + content + "`")
+	case "export":
+		if isTextExportBackend(b.Parameters[0]) {
+			w.WriteString(content)
+		}
+	}
+}
+
+func (w *TextWriter) WriteExample(e Example) {
+	lines := []string{}
+	for _, n := range e.Children {
+		lines = append(lines, w.linesContent([]Node{n}))
+	}
+	w.writeIndented(strings.Join(lines, "\n"), "    ")
+}
+
+func (w *TextWriter) WriteDrawer(d Drawer) {
+	WriteNodes(w, d.Children...)
+}
+
+func (w *TextWriter) WriteClock(c Clock) {
+	if w.document.GetOption("c") == "nil" {
+		return
+	}
+	w.startBlock()
+	w.WriteString("CLOCK: " + String(c.Timestamp))
+	if c.Timestamp.End != nil {
+		w.WriteString(" (" + formatClockDuration(c.Duration) + ")")
+	}
+	w.WriteString("\n")
+}
+
+func (w *TextWriter) WriteLogEntry(e LogEntry) { WriteNodes(w, e.Item) }
+
+func (w *TextWriter) WriteFootnoteDefinition(f FootnoteDefinition) {
+	w.footnotes.updateDefinition(f)
+}
+
+// WriteFootnotes writes the footnote definitions of all footnotes referenced in the document - numbered
+// in order of their first reference like the footnotes of HTMLWriter.
+func (w *TextWriter) WriteFootnotes(d *Document) {
+	if w.document.GetOption("f") == "nil" || len(w.footnotes.list) == 0 {
+		return
+	}
+	w.writeHeading("Footnotes", 1)
+	// iterate by index instead of ranging, since new footnotes can be added when writing the definitions
+	for i := 0; i < len(w.footnotes.list); i++ {
+		definition := w.footnotes.list[i]
+		if definition == nil {
+			continue
+		}
+		w.startBlock()
+		marker := fmt.Sprintf("[%d] ", i+1)
+		content := strings.Trim(w.writeWithWidth(w.width-len(marker), definition.Children...), "\n")
+		w.WriteString(hangingIndent(marker, strings.Repeat(" ", len(marker)), content) + "\n")
+	}
+}
+
+func (w *TextWriter) WriteFootnoteLink(l FootnoteLink) {
+	if w.document.GetOption("f") == "nil" {
+		return
+	}
+	w.WriteString(fmt.Sprintf("[%d]", w.footnotes.add(l)+1))
+}
+
+func (w *TextWriter) WriteList(l List) {
+	if !w.tightList {
+		w.startBlock()
+	}
+	w.tightList = false
+	w.listDepth++
+	WriteNodes(w, l.Items...)
+	w.listDepth--
+}
+
+func (w *TextWriter) WriteListItem(li ListItem) {
+	bullets := w.charset().bullets
+	bullet := bullets[(w.listDepth-1)%len(bullets)]
+	if m := orderedListBulletRegexp.FindStringSubmatch(li.Bullet); m != nil {
+		bullet = li.Bullet
+		if li.Value != "" {
+			bullet = li.Value + m[1]
+		}
+	}
+	prefix := bullet + " "
+	if li.Status != "" {
+		prefix += textListItemStatuses[li.Status] + " "
+	}
+	w.writeListItem(prefix, strings.Repeat(" ", textWidth(bullet)+1), li.Children)
+}
+
+func (w *TextWriter) WriteDescriptiveListItem(di DescriptiveListItem) {
+	bullets := w.charset().bullets
+	bullet := bullets[(w.listDepth-1)%len(bullets)]
+	prefix := bullet + " "
+	if di.Status != "" {
+		prefix += textListItemStatuses[di.Status] + " "
+	}
+	if len(di.Term) != 0 {
+		prefix += w.plainTitle(di.Term) + ": "
+	}
+	w.writeListItem(prefix, strings.Repeat(" ", textWidth(bullet)+1), di.Details)
+}
+
+func (w *TextWriter) writeListItem(prefix, indent string, children []Node) {
+	original, width := w.Builder, w.width
+	w.Builder, w.width = strings.Builder{}, width-len(indent)
+	for i, n := range children {
+		if _, isList := n.(List); isList && i != 0 {
+			_, w.tightList = children[i-1].(Paragraph)
+		}
+		if _, isParagraph := n.(Paragraph); isParagraph && i == 0 {
+			// the first line of the item is shortened by the part of the prefix that is not part of the indentation
+			w.width -= textWidth(prefix) - len(indent)
+			WriteNodes(w, n)
+			w.width += textWidth(prefix) - len(indent)
+			continue
+		}
+		WriteNodes(w, n)
+	}
+	content := strings.Trim(w.String(), "\n")
+	w.Builder, w.width, w.tightList = original, width, false
+	w.WriteString(strings.TrimRight(hangingIndent(prefix, indent, content), " ") + "\n")
+}
+
+// WriteTable draws the table with box characters. Columns are aligned according to their ColumnInfo and
+// cut off at its DisplayLen (e.g. <10>) if set.
+func (w *TextWriter) WriteTable(t Table) {
+	if len(t.ColumnInfos) == 0 {
+		return
+	}
+	rows, widths := [][]string{}, make([]int, len(t.ColumnInfos))
+	for _, row := range t.Rows {
+		if len(row.Columns) == 0 {
+			rows = append(rows, nil)
+			continue
+		} else if row.IsSpecial {
+			continue
+		}
+		cells := make([]string, len(t.ColumnInfos))
+		for i, column := range row.Columns {
+			if i < len(cells) {
+				cells[i] = strings.Join(strings.Fields(w.WriteNodesAsString(column.Children...)), " ")
+				if l := textWidth(cells[i]); l > widths[i] {
+					widths[i] = l
+				}
+			}
+		}
+		rows = append(rows, cells)
+	}
+	for i, info := range t.ColumnInfos {
+		if info.DisplayLen != 0 {
+			widths[i] = info.DisplayLen
+		}
+	}
+	charset := w.charset()
+	border := func(corners [3]string) string {
+		segments := make([]string, len(widths))
+		for i, width := range widths {
+			segments[i] = strings.Repeat(charset.horizontal, width+2)
+		}
+		return corners[0] + strings.Join(segments, corners[1]) + corners[2] + "\n"
+	}
+	w.startBlock()
+	w.WriteString(border(charset.corners[0]))
+	for i, cells := range rows {
+		if cells == nil {
+			if i != 0 && i != len(rows)-1 {
+				w.WriteString(border(charset.corners[1]))
+			}
+			continue
+		}
+		for j, cell := range cells {
+			w.WriteString(charset.vertical + " " + alignCell(truncate(cell, widths[j], charset.ellipsis), widths[j], t.ColumnInfos[j].Align) + " ")
+		}
+		w.WriteString(charset.vertical + "\n")
+	}
+	w.WriteString(border(charset.corners[2]))
+}
+
+func (w *TextWriter) WriteHorizontalRule(HorizontalRule) {
+	w.startBlock()
+	width := max(w.width, 1)
+	if w.Width <= 0 {
+		width = defaultTextWidth
+	}
+	w.WriteString(strings.Repeat(w.charset().horizontal, width) + "\n")
+}
+
+func (w *TextWriter) WriteParagraph(p Paragraph) {
+	content := w.WriteNodesAsString(p.Children...)
+	if strings.TrimSpace(content) == "" {
+		return
+	}
+	if !w.keepLines {
+		content = wrapText(content, w.wrapWidth())
+	}
+	w.startBlock()
+	w.WriteString(strings.Trim(content, "\n") + "\n")
+}
+
+func (w *TextWriter) WriteNodeWithMeta(n NodeWithMeta) {
+	WriteNodes(w, n.Node)
+	if len(n.Meta.Caption) != 0 {
+		captions := []string{}
+		for _, ns := range n.Meta.Caption {
+			captions = append(captions, w.WriteNodesAsString(ns...))
+		}
+		w.startBlock()
+		w.WriteString(wrapText(strings.Join(captions, " "), w.wrapWidth()) + "\n")
+	}
+}
+
+func (w *TextWriter) WriteNodeWithName(n NodeWithName) {
+	WriteNodes(w, n.Node)
+}
+
+func (w *TextWriter) WriteText(t Text) {
+	if w.document.GetOption("e") == "nil" || t.IsRaw {
+		w.WriteString(t.Content)
+	} else if w.ASCII {
+		w.WriteString(asciiEntityReplacer.Replace(t.Content))
+	} else {
+		w.WriteString(htmlEntityReplacer.Replace(t.Content))
+	}
+}
+
+func (w *TextWriter) WriteEmphasis(e Emphasis) {
+	borders, ok := emphasisTextBorders[e.Kind]
+	if !ok {
+		panic(fmt.Sprintf("bad emphasis %#v", e))
+	}
+	w.WriteString(borders[0])
+	WriteNodes(w, e.Content...)
+	w.WriteString(borders[1])
+}
+
+func (w *TextWriter) WriteLatexFragment(l LatexFragment) {
+	w.WriteString(l.OpeningPair + w.WriteNodesAsString(l.Content...) + l.ClosingPair)
+}
+
+func (w *TextWriter) WriteStatisticToken(s StatisticToken) {
+	w.WriteString("[" + s.Content + "]")
+}
+
+// WriteLineBreak writes a space - paragraphs are wrapped at Width anyways (see keepLines).
+func (w *TextWriter) WriteLineBreak(l LineBreak) {
+	if w.keepLines {
+		w.WriteString(strings.Repeat("\n", l.Count))
+	} else if w.document.GetOption("ealb") == "nil" || !l.BetweenMultibyteCharacters {
+		w.WriteString(" ")
+	}
+}
+
+func (w *TextWriter) WriteExplicitLineBreak(ExplicitLineBreak) {
+	w.WriteString("\n")
+}
+
+func (w *TextWriter) WriteTimestamp(t Timestamp) {
+	if w.document.GetOption("<") != "nil" {
+		w.WriteString(String(t))
+	}
+}
+
+// WriteRegularLink writes links with a description as reference to the list of links at the end of the section.
+func (w *TextWriter) WriteRegularLink(l RegularLink) {
+	if _, title, ok := w.document.ResolveInternalLink(l); ok {
+		description := l.Description
+		if description == nil {
+			description = title
+		}
+		w.WriteString(w.WriteNodesAsString(description...))
+		return
+	}
+	url := w.url(l)
+	if l.AutoLink || l.Description == nil {
+		w.WriteString(url)
+		return
+	}
+	description := strings.Join(strings.Fields(w.WriteNodesAsString(l.Description...)), " ")
+	w.WriteString("[" + description + "]")
+	w.links = append(w.links, "["+description+"] <"+url+">")
+}
+
+// url returns the url of the link - with #+LINK abbreviations expanded.
+func (w *TextWriter) url(l RegularLink) string {
+	url := l.URL
+	if l.Protocol == "file" {
+		url = url[len("file:"):]
+	}
+	if prefix := w.document.Links[l.Protocol]; prefix != "" {
+		if tag := strings.TrimPrefix(l.URL, l.Protocol+":"); strings.Contains(prefix, "%s") || strings.Contains(prefix, "%h") {
+			url = strings.ReplaceAll(strings.ReplaceAll(prefix, "%s", tag), "%h", u.QueryEscape(tag))
+		} else {
+			url = prefix + tag
+		}
+	} else if prefix := w.document.Links[l.URL]; prefix != "" {
+		url = strings.ReplaceAll(strings.ReplaceAll(prefix, "%s", ""), "%h", "")
+	}
+	return url
+}
+
+// writeLinks writes the list of links referenced in the current section.
+func (w *TextWriter) writeLinks() {
+	if len(w.links) == 0 {
+		return
+	}
+	w.startBlock()
+	for _, l := range w.links {
+		w.WriteString(l + "\n")
+	}
+	w.links = nil
+}
+
+func (w *TextWriter) WriteTarget(t Target) {}
+
+func (w *TextWriter) WriteRadioTarget(t RadioTarget) {
+	w.WriteString(t.Name)
+}
+
+func (w *TextWriter) WriteMacro(m Macro) {
+	if macro := w.document.Macros[m.Name]; macro != "" {
+		for i, param := range m.Parameters {
+			macro = strings.Replace(macro, fmt.Sprintf("$%d", i+1), param, -1)
+		}
+		macroDocument := w.document.Parse(strings.NewReader(macro), w.document.Path)
+		if macroDocument.Error != nil {
+			w.Diagnostics = append(w.Diagnostics, w.document.diagnostic(SeverityWarning, CodeBadMacro, m.Span, "bad macro: %s -> %s: %v", m.Name, macro, macroDocument.Error))
+		}
+		nodes := macroDocument.Nodes
+		if len(nodes) == 1 {
+			if p, ok := nodes[0].(Paragraph); ok {
+				nodes = p.Children
+			}
+		}
+		w.WriteString(strings.TrimSpace(w.WriteNodesAsString(nodes...)))
+	}
+}
+
+func (w *TextWriter) charset() textCharset {
+	if w.ASCII {
+		return asciiTextCharset
+	}
+	return utf8TextCharset
+}
+
+// startBlock separates the block that is written next from the previous one by a blank line.
+func (w *TextWriter) startBlock() {
+	if out := w.String(); out != "" && !strings.HasSuffix(out, "\n\n") {
+		w.WriteString("\n")
+	}
+}
+
+// writeIndented writes content (e.g. of a code block) indented by indent and without wrapping it.
+func (w *TextWriter) writeIndented(content, indent string) {
+	w.startBlock()
+	w.WriteString(prefixLines(strings.Trim(content, "\n"), indent) + "\n")
+}
+
+// linesContent returns nodes written without wrapping or joining their lines (e.g. for verse and source blocks).
+func (w *TextWriter) linesContent(nodes []Node) string {
+	keepLines := w.keepLines
+	w.keepLines = true
+	out := w.WriteNodesAsString(nodes...)
+	w.keepLines = keepLines
+	return out
+}
+
+// writeWithWidth returns nodes written as if the available width was width (e.g. for indented content).
+func (w *TextWriter) writeWithWidth(width int, nodes ...Node) string {
+	original := w.width
+	w.width = width
+	out := w.WriteNodesAsString(nodes...)
+	w.width = original
+	return out
+}
+
+func (w *TextWriter) blockContent(name string, children []Node) string {
+	if isRawTextBlock(name) {
+		return strings.TrimRightFunc(strings.TrimLeftFunc(w.linesContent(children), IsNewLineChar), unicode.IsSpace)
+	} else if name == "VERSE" {
+		return w.linesContent(children)
+	}
+	width, tightList, listDepth := w.width, w.tightList, w.listDepth
+	if name == "QUOTE" {
+		width -= 4
+	}
+	w.tightList, w.listDepth = false, 0
+	out := w.writeWithWidth(width, children...)
+	w.tightList, w.listDepth = tightList, listDepth
+	return out
+}
+
+func isTextExportBackend(backend string) bool {
+	switch strings.ToLower(backend) {
+	case "ascii", "txt", "text":
+		return true
+	}
+	return false
+}
+
+// nextSectionNumber returns the section numbers after a headline of level lvl and the number of that headline (e.g. 1.2).
+func nextSectionNumber(numbers []int, lvl int) ([]int, string) {
+	for len(numbers) < lvl {
+		numbers = append(numbers, 0)
+	}
+	numbers = numbers[:lvl]
+	numbers[lvl-1]++
+	parts := make([]string, lvl)
+	for i, n := range numbers {
+		parts[i] = strconv.Itoa(n)
+	}
+	return numbers, strings.Join(parts, ".")
+}
+
+// wrapWidth returns the width paragraphs are wrapped at - 0 if they are not wrapped (see Width).
+func (w *TextWriter) wrapWidth() int {
+	if w.Width <= 0 {
+		return 0
+	}
+	return max(w.width, 1)
+}
+
+// wrapText wraps the lines of s at width. Whitespace is collapsed and words longer than width are not broken.
+// Lines are not wrapped if width is 0 or negative.
+func wrapText(s string, width int) string {
+	lines := []string{}
+	for _, paragraph := range strings.Split(strings.Trim(s, "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			if line == "" {
+				line = word
+			} else if width <= 0 || textWidth(line)+1+textWidth(word) <= width {
+				line += " " + word
+			} else {
+				lines, line = append(lines, line), word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// textWidth returns the number of columns s takes up in a terminal - east asian wide characters take up two columns.
+func textWidth(s string) int {
+	width := 0
+	for _, r := range s {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+		case unicode.In(r, unicode.Han, unicode.Hangul, unicode.Hiragana, unicode.Katakana), r >= 0xFF01 && r <= 0xFF60:
+			width += 2
+		default:
+			width++
+		}
+	}
+	return width
+}
+
+func centerLine(s string, width int) string {
+	if padding := (width - textWidth(s)) / 2; padding > 0 && strings.TrimSpace(s) != "" {
+		return strings.Repeat(" ", padding) + s
+	}
+	return s
+}
+
+func alignCell(s string, width int, align string) string {
+	padding := width - textWidth(s)
+	if padding <= 0 {
+		return s
+	}
+	switch align {
+	case "right":
+		return strings.Repeat(" ", padding) + s
+	case "center":
+		return strings.Repeat(" ", padding/2) + s + strings.Repeat(" ", padding-padding/2)
+	default:
+		return s + strings.Repeat(" ", padding)
+	}
+}
+
+// truncate cuts s off at width - the cut off part is replaced with ellipsis.
+func truncate(s string, width int, ellipsis string) string {
+	if textWidth(s) <= width {
+		return s
+	}
+	out, max := "", width-textWidth(ellipsis)
+	for _, r := range s {
+		if textWidth(out+string(r)) > max {
+			break
+		}
+		out += string(r)
+	}
+	return out + ellipsis
+}
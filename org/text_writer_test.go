@@ -0,0 +1,66 @@
+package org
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTextWriter(t *testing.T) {
+	testWriter(t, func() Writer { return NewTextWriter() }, ".txt")
+}
+
+func TestTextWriterOptions(t *testing.T) {
+	input := `#+OPTIONS: toc:nil num:t
+
+* Report
+Progress on the \alpha release is tracked in [[https://example.com/board][the board]].
+** Numbers
+| metric      | value |
+|-------------+-------|
+| open issues |    12 |
+`
+	expected := `1 Report
+========
+
+Progress on the alpha release is tracked
+in [the board].
+
+[the board] <https://example.com/board>
+
+1.1 Numbers
+~~~~~~~~~~~
+
++-------------+-------+
+| metric      | value |
++-------------+-------+
+| open issues |    12 |
++-------------+-------+
+`
+	w := NewTextWriter()
+	w.Width, w.ASCII = 40, true
+	actual, err := New().Silent().Parse(strings.NewReader(input), "./test.org").Write(w)
+	if err != nil {
+		t.Fatal(err)
+	} else if actual != expected {
+		t.Errorf("got\n%s\nexpected\n%s\n%s", actual, expected, diff(actual, expected))
+	}
+}
+
+func TestWrapText(t *testing.T) {
+	for _, c := range []struct {
+		input    string
+		width    int
+		expected string
+	}{
+		{"a b c d", 3, "a b\nc d"},
+		{"a  verylongword b", 5, "a\nverylongword\nb"},
+		{"keep\nexplicit  line breaks", 10, "keep\nexplicit\nline\nbreaks"},
+		{"東京 大阪 名古屋", 10, "東京 大阪\n名古屋"},
+		{"no  wrapping\nat width 0", 0, "no wrapping\nat width 0"},
+		{"or negative widths", -1, "or negative widths"},
+	} {
+		if actual := wrapText(c.input, c.width); actual != c.expected {
+			t.Errorf("wrapText(%q, %d): got %q, expected %q", c.input, c.width, actual, c.expected)
+		}
+	}
+}
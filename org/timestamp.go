@@ -0,0 +1,137 @@
+package org
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Timestamp struct {
+	Time        time.Time
+	IsDate      bool
+	Interval    string // Interval is the repeater, e.g. +1w (cumulate), ++1w (catch-up) or .+1w (restart).
+	IsInactive  bool
+	Warning     string     // Warning is the warning delay, e.g. -2d (all) or --2d (first).
+	End         *Timestamp // End is the end of a date range (<...>--<...>) or of a time range (<... 10:00-12:00>) - see IsTimeRange.
+	IsTimeRange bool
+	Diary       string // Diary is the sexp of a diary timestamp (<%%(...)>).
+	Span        Span
+}
+
+var timestampRegexp = regexp.MustCompile(`^([<\[])(\d{4}-\d{2}-\d{2})(?: +\p{L}+\.?)?(?: +(\d{1,2}:\d{2})(?:-(\d{1,2}:\d{2}))?)?((?: +(?:\+\+|\.\+|\+|--|-)\d+[hdwmy])*) *([>\]])`)
+var timestampModifierRegexp = regexp.MustCompile(`^(\+\+|\.\+|\+|--|-)(\d+)([hdwmy])$`)
+var diaryTimestampRegexp = regexp.MustCompile(`^<%%(\([^>\n]*\))>`)
+
+var timestampFormat = "2006-01-02 Mon 15:04"
+var datestampFormat = "2006-01-02 Mon"
+
+func (d *Document) parseTimestamp(input string, start int, at offsetFn) (int, Node) {
+	if m := diaryTimestampRegexp.FindStringSubmatch(input[start:]); m != nil {
+		return len(m[0]), Timestamp{Diary: m[1]}
+	}
+	m := timestampRegexp.FindStringSubmatch(input[start:])
+	if m == nil || (m[1] == "<") != (m[6] == ">") {
+		return 0, nil
+	}
+	ddmmyy, hhmm, isDate := m[2], m[3], false
+	if hhmm == "" {
+		hhmm, isDate = "00:00", true
+	}
+	t, err := time.Parse(timestampFormat, fmt.Sprintf("%s Mon %s", ddmmyy, hhmm))
+	if err != nil {
+		return 0, nil
+	}
+	timestamp := Timestamp{Time: t, IsDate: isDate, IsInactive: m[1] == "["}
+	for _, modifier := range strings.Fields(m[5]) {
+		if modifier[0] == '-' {
+			timestamp.Warning = modifier
+		} else {
+			timestamp.Interval = modifier
+		}
+	}
+	if m[4] != "" {
+		end, err := time.Parse(timestampFormat, fmt.Sprintf("%s Mon %s", ddmmyy, m[4]))
+		if err != nil {
+			return 0, nil
+		}
+		timestamp.End, timestamp.IsTimeRange = &Timestamp{Time: end, IsInactive: timestamp.IsInactive}, true
+	}
+	consumed := len(m[0])
+	if !timestamp.IsTimeRange && strings.HasPrefix(input[start+consumed:], "--") {
+		i := start + consumed + 2
+		if c, node := d.parseTimestamp(input, i, at); c != 0 {
+			if end := node.(Timestamp); end.IsInactive == timestamp.IsInactive && end.End == nil && end.Diary == "" {
+				end.Span = d.inlineSpan(at, i, i+c)
+				timestamp.End, consumed = &end, consumed+2+c
+			}
+		}
+	}
+	return consumed, timestamp
+}
+
+// Repeater returns the kind (+, ++ or .+), value and unit (h, d, w, m or y) of the repeater of t.
+func (t Timestamp) Repeater() (kind string, value int, unit string) {
+	return parseTimestampModifier(t.Interval)
+}
+
+// WarningDelay returns the kind (- or --), value and unit (h, d, w, m or y) of the warning delay of t.
+func (t Timestamp) WarningDelay() (kind string, value int, unit string) {
+	return parseTimestampModifier(t.Warning)
+}
+
+// Occurrences returns the start times of t (and its repetitions, if it has a repeater) in [from, to].
+// Diary timestamps are not supported and never occur.
+func (t Timestamp) Occurrences(from, to time.Time) []time.Time {
+	if t.Diary != "" || t.Time.After(to) {
+		return nil
+	}
+	_, value, unit := t.Repeater()
+	if value == 0 {
+		if t.Time.Before(from) {
+			return nil
+		}
+		return []time.Time{t.Time}
+	}
+	occurrences, n := []time.Time{}, 0
+	if d := addTimestampUnit(t.Time, unit, value).Sub(t.Time); unit != "m" && unit != "y" && t.Time.Before(from) {
+		n = int(from.Sub(t.Time) / d)
+	}
+	for ; ; n++ {
+		occurrence := addTimestampUnit(t.Time, unit, n*value)
+		if occurrence.After(to) {
+			break
+		} else if !occurrence.Before(from) {
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+	return occurrences
+}
+
+func parseTimestampModifier(s string) (kind string, value int, unit string) {
+	m := timestampModifierRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return "", 0, ""
+	}
+	value, _ = strconv.Atoi(m[2])
+	return m[1], value, m[3]
+}
+
+func addTimestampUnit(t time.Time, unit string, n int) time.Time {
+	switch unit {
+	case "h":
+		return t.Add(time.Duration(n) * time.Hour)
+	case "d":
+		return t.AddDate(0, 0, n)
+	case "w":
+		return t.AddDate(0, 0, 7*n)
+	case "m":
+		return t.AddDate(0, n, 0)
+	case "y":
+		return t.AddDate(n, 0, 0)
+	}
+	return t
+}
+
+func (n Timestamp) String() string { return String(n) }
@@ -0,0 +1,43 @@
+package org
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+var occurrencesTests = map[string]string{
+	"<2024-01-01 Mon>":        "2024-01-01",
+	"<2023-12-01 Fri>":        "",
+	"<2024-01-01 Mon +1w>":    "2024-01-01 2024-01-08 2024-01-15 2024-01-22 2024-01-29",
+	"<2023-12-25 Mon .+1w>":   "2024-01-01 2024-01-08 2024-01-15 2024-01-22 2024-01-29",
+	"<2023-11-30 Thu ++1m>":   "2024-01-30",
+	"<2024-01-10 Wed +10d>":   "2024-01-10 2024-01-20 2024-01-30",
+	"<%%(diary-float t 4 2)>": "",
+}
+
+func TestOccurrences(t *testing.T) {
+	from, to := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	for s, expected := range occurrencesTests {
+		_, node := New().Parse(strings.NewReader(""), "").parseTimestamp(s, 0, nil)
+		occurrences := []string{}
+		for _, o := range node.(Timestamp).Occurrences(from, to) {
+			occurrences = append(occurrences, o.Format("2006-01-02"))
+		}
+		if actual := strings.Join(occurrences, " "); actual != expected {
+			t.Errorf("%s:\n%v", s, diff(fmt.Sprint(actual), fmt.Sprint(expected)))
+		}
+	}
+}
+
+func TestTimestampModifiers(t *testing.T) {
+	_, node := New().Parse(strings.NewReader(""), "").parseTimestamp("<2024-01-01 Mon 10:00 .+2w --3d>", 0, nil)
+	timestamp := node.(Timestamp)
+	if kind, value, unit := timestamp.Repeater(); kind != ".+" || value != 2 || unit != "w" {
+		t.Errorf("unexpected repeater: %s %d %s", kind, value, unit)
+	}
+	if kind, value, unit := timestamp.WarningDelay(); kind != "--" || value != 3 || unit != "d" {
+		t.Errorf("unexpected warning delay: %s %d %s", kind, value, unit)
+	}
+}
@@ -0,0 +1,92 @@
+package org
+
+import (
+	"regexp"
+	"strings"
+)
+
+// TodoSequence is a sequence of TODO keywords as defined by a #+TODO, #+SEQ_TODO or #+TYP_TODO line,
+// e.g. #+TODO: TODO NEXT | DONE CANCELLED.
+type TodoSequence struct {
+	Type string   // Type is either sequence (#+TODO, #+SEQ_TODO) or type (#+TYP_TODO).
+	Todo []string // Todo contains the keywords before the | (i.e. the not done states).
+	Done []string // Done contains the keywords after the | (i.e. the done states).
+}
+
+// TodoKeywords contains all TODO keyword sequences of a document.
+type TodoKeywords []TodoSequence
+
+var todoKeywordSettings = []struct{ key, kind string }{{"TODO", "sequence"}, {"SEQ_TODO", "sequence"}, {"TYP_TODO", "type"}}
+var todoFastAccessRegexp = regexp.MustCompile(`^(.+?)\([^()]*\)$`)
+
+// TodoKeywords returns the TODO keyword sequences of all #+TODO, #+SEQ_TODO and #+TYP_TODO lines.
+// If the document does not contain any of them, the sequences of the DefaultSettings are returned.
+func (d *Document) TodoKeywords() TodoKeywords {
+	settings := d.DefaultSettings
+	for _, s := range todoKeywordSettings {
+		if _, ok := d.BufferSettings[s.key]; ok {
+			settings = d.BufferSettings
+			break
+		}
+	}
+	keywords := TodoKeywords{}
+	for _, s := range todoKeywordSettings {
+		for _, line := range strings.Split(settings[s.key], "\n") {
+			if sequence, ok := parseTodoSequence(s.kind, line); ok {
+				keywords = append(keywords, sequence)
+			}
+		}
+	}
+	return keywords
+}
+
+func parseTodoSequence(kind, line string) (TodoSequence, bool) {
+	parts := strings.SplitN(line, "|", 2)
+	todo, done := trimFastTags(strings.Fields(parts[0])), []string{}
+	if len(parts) == 2 {
+		done = trimFastTags(strings.Fields(parts[1]))
+	} else if len(todo) != 0 {
+		todo, done = todo[:len(todo)-1], todo[len(todo)-1:]
+	}
+	if len(todo) == 0 && len(done) == 0 {
+		return TodoSequence{}, false
+	}
+	return TodoSequence{kind, todo, done}, true
+}
+
+// Keywords returns all keywords of all sequences in order.
+func (ks TodoKeywords) Keywords() []string {
+	keywords := []string{}
+	for _, s := range ks {
+		keywords = append(append(keywords, s.Todo...), s.Done...)
+	}
+	return keywords
+}
+
+// Sequence returns the first sequence that contains the keyword status.
+func (ks TodoKeywords) Sequence(status string) (TodoSequence, bool) {
+	for _, s := range ks {
+		if containsString(s.Todo, status) || containsString(s.Done, status) {
+			return s, true
+		}
+	}
+	return TodoSequence{}, false
+}
+
+// IsDone returns true if status is a done keyword.
+func (ks TodoKeywords) IsDone(status string) bool {
+	s, ok := ks.Sequence(status)
+	return ok && containsString(s.Done, status)
+}
+
+func trimFastTags(tags []string) []string {
+	trimmedTags := make([]string, len(tags))
+	for i, t := range tags {
+		if m := todoFastAccessRegexp.FindStringSubmatch(t); m != nil {
+			trimmedTags[i] = m[1]
+		} else {
+			trimmedTags[i] = t
+		}
+	}
+	return trimmedTags
+}
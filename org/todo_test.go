@@ -0,0 +1,30 @@
+package org
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestTodoKeywords(t *testing.T) {
+	d := New().Silent().Parse(strings.NewReader(`#+TODO: TODO(t) NEXT(n) | DONE(d!) CANCELED(c@/!)
+#+SEQ_TODO: REPORT BUG KNOWNCAUSE | FIXED
+#+TYP_TODO: Fred Sara Lucy
+`), "")
+	ks := d.TodoKeywords()
+	expected := "[{sequence [TODO NEXT] [DONE CANCELED]} {sequence [REPORT BUG KNOWNCAUSE] [FIXED]} {type [Fred Sara] [Lucy]}]"
+	if actual := fmt.Sprint(ks); actual != expected {
+		t.Errorf("unexpected keywords:\n%s", diff(actual, expected))
+	}
+	for status, isDone := range map[string]bool{"TODO": false, "CANCELED": true, "BUG": false, "FIXED": true, "Lucy": true, "UNKNOWN": false} {
+		if ks.IsDone(status) != isDone {
+			t.Errorf("expected IsDone(%s) to be %v", status, isDone)
+		}
+	}
+	if s, ok := ks.Sequence("KNOWNCAUSE"); !ok || s.Done[0] != "FIXED" {
+		t.Errorf("unexpected sequence for KNOWNCAUSE: %v", s)
+	}
+	if actual := fmt.Sprint(New().Parse(strings.NewReader(""), "").TodoKeywords()); actual != "[{sequence [TODO] [DONE]}]" {
+		t.Errorf("unexpected default keywords: %s", actual)
+	}
+}
@@ -0,0 +1,250 @@
+package org
+
+// A Visitor's Visit method is called for each node encountered by Walk. If the returned Visitor w is not nil,
+// Walk visits each of the children of the node with w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(n Node) (w Visitor)
+}
+
+type inspector func(Node) bool
+
+// Walk traverses the given nodes and their children in depth-first order (see Visitor).
+// The children of a node are the nodes of all its fields - e.g. the Title, planning timestamps (in the order
+// of the planning line), Properties and Children of a Headline, the Description of a RegularLink or the Children
+// of all Columns of a Table. Headline.Clocks are not visited as they are the Clock nodes of Headline.Children.
+func Walk(v Visitor, nodes ...Node) {
+	for _, n := range nodes {
+		if n == nil {
+			continue
+		}
+		if w := v.Visit(n); w != nil {
+			Walk(w, children(n)...)
+			w.Visit(nil)
+		}
+	}
+}
+
+// Inspect traverses the given nodes and their children in depth-first order (see Walk).
+// f is called for each node - the children of a node are only traversed if f returns true.
+func Inspect(f func(Node) bool, nodes ...Node) {
+	Walk(inspector(f), nodes...)
+}
+
+func (f inspector) Visit(n Node) Visitor {
+	if n != nil && f(n) {
+		return f
+	}
+	return nil
+}
+
+// Transform returns a copy of nodes with f applied to every node - nodes are not modified in place.
+// f is called bottom up, i.e. with nodes whose children have already been transformed, and returns the nodes
+// that replace the node: Return []Node{n} to keep n, nil to delete it or multiple nodes to insert nodes.
+// Fields that hold a single node (e.g. NodeWithName.Node) are set to the first replacement node or nil if there is none.
+// Headline.Clocks are updated to the Clock nodes of the transformed Headline.Children.
+// Note that Document.Outline is not updated when transforming Document.Nodes.
+func Transform(nodes []Node, f func(Node) []Node) []Node {
+	if nodes == nil {
+		return nil
+	}
+	transformed := []Node{}
+	for _, n := range nodes {
+		if n == nil {
+			continue
+		}
+		transformed = append(transformed, f(transformChildren(n, f))...)
+	}
+	return transformed
+}
+
+func transformNode(n Node, f func(Node) []Node) Node {
+	if nodes := Transform([]Node{n}, f); len(nodes) != 0 {
+		return nodes[0]
+	}
+	return nil
+}
+
+func children(n Node) []Node {
+	switch n := n.(type) {
+	case NodeWithMeta:
+		nodes := []Node{}
+		for _, caption := range n.Meta.Caption {
+			nodes = append(nodes, caption...)
+		}
+		return append(nodes, n.Node)
+	case NodeWithName:
+		return []Node{n.Node}
+	case Headline:
+		nodes := append([]Node{}, n.Title...)
+		for _, p := range n.Planning() {
+			nodes = append(nodes, *p.Timestamp)
+		}
+		if n.Properties != nil {
+			nodes = append(nodes, *n.Properties)
+		}
+		return append(nodes, n.Children...)
+	case Block:
+		return append(append([]Node{}, n.Children...), n.Result)
+	case Result:
+		return []Node{n.Node}
+	case LatexBlock:
+		return n.Content
+	case InlineBlock:
+		return n.Children
+	case Example:
+		return n.Children
+	case Drawer:
+		return n.Children
+	case List:
+		return n.Items
+	case ListItem:
+		return n.Children
+	case DescriptiveListItem:
+		return append(append([]Node{}, n.Term...), n.Details...)
+	case Table:
+		nodes := []Node{}
+		for _, row := range n.Rows {
+			for _, column := range row.Columns {
+				nodes = append(nodes, column.Children...)
+			}
+		}
+		return nodes
+	case Paragraph:
+		return n.Children
+	case Emphasis:
+		return n.Content
+	case LatexFragment:
+		return n.Content
+	case RegularLink:
+		return n.Description
+	case FootnoteLink:
+		if n.Definition != nil {
+			return []Node{*n.Definition}
+		}
+	case FootnoteDefinition:
+		return n.Children
+	case Clock:
+		return []Node{n.Timestamp}
+	case LogEntry:
+		return []Node{n.Item}
+	}
+	return nil
+}
+
+func transformChildren(n Node, f func(Node) []Node) Node {
+	switch n := n.(type) {
+	case NodeWithMeta:
+		if n.Meta.Caption != nil {
+			captions := make([][]Node, len(n.Meta.Caption))
+			for i, caption := range n.Meta.Caption {
+				captions[i] = Transform(caption, f)
+			}
+			n.Meta.Caption = captions
+		}
+		n.Node = transformNode(n.Node, f)
+		return n
+	case NodeWithName:
+		n.Node = transformNode(n.Node, f)
+		return n
+	case Headline:
+		n.Title = Transform(n.Title, f)
+		planning := map[string]**Timestamp{"CLOSED": &n.Closed, "DEADLINE": &n.Deadline, "SCHEDULED": &n.Scheduled}
+		for _, p := range n.Planning() {
+			if timestamp, ok := transformNode(*p.Timestamp, f).(Timestamp); ok {
+				*planning[p.Keyword] = &timestamp
+			} else {
+				*planning[p.Keyword] = nil
+			}
+		}
+		if n.Properties != nil {
+			if drawer, ok := transformNode(*n.Properties, f).(PropertyDrawer); ok {
+				n.Properties = &drawer
+			} else {
+				n.Properties = nil
+			}
+		}
+		n.Children = Transform(n.Children, f)
+		if n.Clocks != nil {
+			n.Clocks = collectClocks(n.Children)
+		}
+		return n
+	case Block:
+		n.Children = Transform(n.Children, f)
+		if n.Result != nil {
+			n.Result = transformNode(n.Result, f)
+		}
+		return n
+	case Result:
+		n.Node = transformNode(n.Node, f)
+		return n
+	case LatexBlock:
+		n.Content = Transform(n.Content, f)
+		return n
+	case InlineBlock:
+		n.Children = Transform(n.Children, f)
+		return n
+	case Example:
+		n.Children = Transform(n.Children, f)
+		return n
+	case Drawer:
+		n.Children = Transform(n.Children, f)
+		return n
+	case List:
+		n.Items = Transform(n.Items, f)
+		return n
+	case ListItem:
+		n.Children = Transform(n.Children, f)
+		return n
+	case DescriptiveListItem:
+		n.Term, n.Details = Transform(n.Term, f), Transform(n.Details, f)
+		return n
+	case Table:
+		rows := make([]Row, len(n.Rows))
+		for i, row := range n.Rows {
+			rows[i] = row
+			if row.Columns != nil {
+				rows[i].Columns = make([]Column, len(row.Columns))
+				for j, column := range row.Columns {
+					rows[i].Columns[j] = Column{Transform(column.Children, f), column.ColumnInfo}
+				}
+			}
+		}
+		n.Rows = rows
+		return n
+	case Paragraph:
+		n.Children = Transform(n.Children, f)
+		return n
+	case Emphasis:
+		n.Content = Transform(n.Content, f)
+		return n
+	case LatexFragment:
+		n.Content = Transform(n.Content, f)
+		return n
+	case RegularLink:
+		n.Description = Transform(n.Description, f)
+		return n
+	case FootnoteLink:
+		if n.Definition != nil {
+			if definition, ok := transformNode(*n.Definition, f).(FootnoteDefinition); ok {
+				n.Definition = &definition
+			} else {
+				n.Definition = nil
+			}
+		}
+		return n
+	case FootnoteDefinition:
+		n.Children = Transform(n.Children, f)
+		return n
+	case Clock:
+		if timestamp, ok := transformNode(n.Timestamp, f).(Timestamp); ok {
+			n.Timestamp = timestamp
+		}
+		return n
+	case LogEntry:
+		if item, ok := transformNode(n.Item, f).(ListItem); ok {
+			n.Item = item
+		}
+		return n
+	}
+	return n
+}
@@ -0,0 +1,120 @@
+package org
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var walkTestInput = `* headline [[https://example.com][*link*]]
+# comment
+| [[https://example.com/table]] |
+- item with footnote[fn::see [[https://example.com/footnote]]]
+`
+
+func TestInspect(t *testing.T) {
+	d := New().Silent().Parse(strings.NewReader(walkTestInput), "./walk.org")
+	urls := []string{}
+	Inspect(func(n Node) bool {
+		if l, ok := n.(RegularLink); ok {
+			urls = append(urls, l.URL)
+		}
+		return true
+	}, d.Nodes...)
+	if expected := "https://example.com https://example.com/table https://example.com/footnote"; strings.Join(urls, " ") != expected {
+		t.Errorf("got %q, expected %q", urls, expected)
+	}
+	count := 0
+	Inspect(func(n Node) bool {
+		count++
+		_, isHeadline := n.(Headline)
+		return !isHeadline
+	}, d.Nodes...)
+	if count != 1 {
+		t.Errorf("children of headline were inspected: %d nodes", count)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	d := New().Silent().Parse(strings.NewReader(walkTestInput), "./walk.org")
+	nodes := Transform(d.Nodes, func(n Node) []Node {
+		switch n := n.(type) {
+		case Headline:
+			n.Lvl++
+			return []Node{n, HorizontalRule{}}
+		case RegularLink:
+			n.URL = strings.Replace(n.URL, "example.com", "example.org", 1)
+			return []Node{n}
+		case Comment:
+			return nil
+		}
+		return []Node{n}
+	})
+	expected := `** headline [[https://example.org][*link*]]
+| [[https://example.org/table]] |
+- item with footnote[fn::see [[https://example.org/footnote]]]
+-----
+`
+	if actual := String(nodes...); actual != expected {
+		t.Errorf("%s", diff(actual, expected))
+	}
+	if original := String(d.Nodes...); !strings.Contains(original, "example.com") || !strings.Contains(original, "# comment") {
+		t.Errorf("original nodes were modified:\n%s", original)
+	}
+}
+
+func TestTransformIdentity(t *testing.T) {
+	for _, path := range orgTestFiles() {
+		d := New().Silent().Parse(strings.NewReader(fileString(t, path)), path)
+		nodes := Transform(d.Nodes, func(n Node) []Node { return []Node{n} })
+		if actual, expected := String(nodes...), String(d.Nodes...); actual != expected {
+			t.Errorf("%s:\n%s", path, diff(actual, expected))
+		}
+	}
+}
+
+func TestTransformKeepsNilFields(t *testing.T) {
+	d := New().Silent().Parse(strings.NewReader("#+ATTR_HTML: :class table\n| a |\n|---|\n| b |\n"), "./walk.org")
+	if nodes := Transform(d.Nodes, func(n Node) []Node { return []Node{n} }); !reflect.DeepEqual(nodes, d.Nodes) {
+		t.Errorf("got %#v, expected %#v", nodes, d.Nodes)
+	}
+}
+
+func TestWalkHeadlineFields(t *testing.T) {
+	input := strings.Join([]string{
+		"* headline",
+		"SCHEDULED: <2024-01-02 Tue> DEADLINE: <2024-01-01 Mon>",
+		":PROPERTIES:",
+		":ID: a",
+		":END:",
+		":LOGBOOK:",
+		"CLOCK: [2024-01-01 Mon 10:00]--[2024-01-01 Mon 11:00] =>  1:00",
+		":END:",
+	}, "\n")
+	d := New().Silent().Parse(strings.NewReader(input), "./walk.org")
+	types := []string{}
+	Inspect(func(n Node) bool {
+		types = append(types, reflect.TypeOf(n).Name())
+		return true
+	}, d.Nodes...)
+	if expected := "Headline Text Timestamp Timestamp PropertyDrawer Drawer Clock Timestamp"; strings.Join(types, " ") != expected {
+		t.Errorf("got %q, expected %q", types, expected)
+	}
+	nodes := Transform(d.Nodes, func(n Node) []Node {
+		switch n := n.(type) {
+		case Timestamp:
+			n.Time = n.Time.AddDate(1, 0, 0)
+			return []Node{n}
+		case PropertyDrawer:
+			return nil
+		}
+		return []Node{n}
+	})
+	h := nodes[0].(Headline)
+	if h.Scheduled.Time.Year() != 2025 || h.Deadline.Time.Year() != 2025 || h.Properties != nil {
+		t.Errorf("planning and properties were not transformed: %#v", h)
+	}
+	if len(h.Clocks) != 1 || h.Clocks[0].Timestamp.Time.Year() != 2025 {
+		t.Errorf("clocks were not updated: %#v", h.Clocks)
+	}
+}
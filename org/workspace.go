@@ -0,0 +1,219 @@
+package org
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Workspace is a directory tree of Org files that link to each other. It indexes the :ID: and CUSTOM_ID properties
+// of all headlines and the #+TITLE of all files, so that links between the files (e.g. [[id:...]] and
+// [[file:other.org::*Heading]]) can be resolved to the linked file and headline - see ConfigurationFor.
+type Workspace struct {
+	Root          string
+	Configuration *Configuration                        // Configuration is used to parse the files of the workspace.
+	Documents     map[string]*Document                  // Documents contains the files by their slash separated path relative to Root.
+	Titles        map[string]string                     // Titles contains the #+TITLE of the files by path.
+	IDs           map[string]WorkspaceTarget            // IDs contains the headlines by their :ID: property.
+	CustomIDs     map[string]map[string]WorkspaceTarget // CustomIDs contains the headlines of each file by their CUSTOM_ID property.
+	Links         []Backlink                            // Links contains the links between the files and headlines of the workspace.
+
+	outlines map[string]Outline
+}
+
+// WorkspaceTarget is a file or a headline of a Workspace that can be linked to.
+type WorkspaceTarget struct {
+	Path  string // Path is the slash separated path of the file relative to the root of the workspace.
+	ID    string // ID is the id of the headline (see Headline.ID). It is empty for files.
+	Title []Node // Title is the title of the headline or the #+TITLE of the file (nil if the file has no title).
+}
+
+// NewWorkspace parses all .org files in the directory tree root (skipping hidden directories) with c.
+// Links between the files are resolved using the index of the workspace (see ConfigurationFor) - files containing
+// such links are parsed again once all files are indexed.
+func NewWorkspace(root string, c *Configuration) (*Workspace, error) {
+	w := &Workspace{root, c, map[string]*Document{}, map[string]string{}, map[string]WorkspaceTarget{}, map[string]map[string]WorkspaceTarget{}, nil, map[string]Outline{}}
+	sources, documents := map[string][]byte{}, map[string]*Document{}
+	err := filepath.WalkDir(root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		} else if entry.IsDir() && p != root && strings.HasPrefix(entry.Name(), ".") {
+			return filepath.SkipDir
+		} else if entry.IsDir() || filepath.Ext(p) != ".org" {
+			return nil
+		}
+		bs, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		key, err := w.key(p)
+		if err != nil {
+			return err
+		}
+		sources[key], documents[key] = bs, c.Parse(bytes.NewReader(bs), p)
+		w.index(key, documents[key])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for key, d := range documents {
+		if w.hasWorkspaceLinks(key, d) {
+			p := filepath.Join(root, filepath.FromSlash(key))
+			d = w.ConfigurationFor(p).Parse(bytes.NewReader(sources[key]), p)
+		}
+		w.Documents[key] = d
+	}
+	w.collectLinks()
+	return w, nil
+}
+
+// ConfigurationFor returns a copy of the Configuration of the workspace for parsing the file at path (a path of the
+// file system like Document.Path). Its ResolveLink resolves id: links and links to files of the workspace
+// (with optional ::*Heading or ::#custom-id search options) into file:path.org::#id links - where path is
+// relative to the file and id is the id of the linked headline (see Headline.ID). HTMLWriter turns these
+// into links to the exported file (e.g. path.html#id). The title of the target is used as description if
+// the link does not have one. Other links are resolved by the ResolveLink of the workspace Configuration.
+func (w *Workspace) ConfigurationFor(path string) *Configuration {
+	c, resolveLink := *w.Configuration, w.Configuration.ResolveLink
+	from, err := w.key(path)
+	c.ResolveLink = func(protocol string, description []Node, link string) Node {
+		target, ok := w.Resolve(from, link)
+		if err != nil || !ok {
+			return resolveLink(protocol, description, link)
+		}
+		if description == nil {
+			description = target.Title
+		}
+		if target.Path == from && target.ID != "" {
+			return RegularLink{"", description, "#" + target.ID, false, Span{}}
+		}
+		url := "file:" + relativePath(from, target.Path)
+		if target.ID != "" {
+			url += "::#" + target.ID
+		}
+		return RegularLink{"file", description, url, false, Span{}}
+	}
+	return &c
+}
+
+// Document returns the document of the workspace parsed from the file at path (a path of the file system).
+func (w *Workspace) Document(path string) (*Document, bool) {
+	key, err := w.key(path)
+	if err != nil {
+		return nil, false
+	}
+	d, ok := w.Documents[key]
+	return d, ok
+}
+
+// Resolve returns the target of link in the file from (a path relative to the root of the workspace).
+// Supported are id:id links and links to .org files of the workspace, with optional search options
+// ::*Heading (the headline with the title) and ::#custom-id (the headline with the CUSTOM_ID or id - see Headline.ID).
+func (w *Workspace) Resolve(from, link string) (WorkspaceTarget, bool) {
+	if strings.HasPrefix(link, "id:") {
+		target, ok := w.IDs[strings.TrimPrefix(link, "id:")]
+		return target, ok
+	}
+	link, search := strings.TrimPrefix(link, "file:"), ""
+	if i := strings.Index(link, "::"); i != -1 {
+		link, search = link[:i], link[i+2:]
+	}
+	if path.Ext(link) != ".org" || strings.Contains(link, "://") {
+		return WorkspaceTarget{}, false
+	}
+	p := path.Join(path.Dir(from), link)
+	if filepath.IsAbs(link) {
+		key, err := w.key(link)
+		if err != nil {
+			return WorkspaceTarget{}, false
+		}
+		p = key
+	}
+	outline, ok := w.outlines[p]
+	if !ok {
+		return WorkspaceTarget{}, false
+	}
+	switch {
+	case strings.HasPrefix(search, "#"):
+		if target, ok := w.CustomIDs[p][search[1:]]; ok {
+			return target, true
+		}
+		return findHeadline(p, outline.Children, func(h *Headline) bool { return h.ID() == search[1:] })
+	case strings.HasPrefix(search, "*"):
+		title := strings.TrimSpace(search[1:])
+		return findHeadline(p, outline.Children, func(h *Headline) bool { return strings.TrimSpace(String(h.Title...)) == title })
+	case search != "":
+		return WorkspaceTarget{}, false
+	}
+	target := WorkspaceTarget{Path: p}
+	if title := w.Titles[p]; title != "" {
+		target.Title = []Node{Text{Content: title}}
+	}
+	return target, true
+}
+
+func (w *Workspace) index(key string, d *Document) {
+	w.Titles[key], w.CustomIDs[key], w.outlines[key] = d.Get("TITLE"), map[string]WorkspaceTarget{}, d.Outline
+	Inspect(func(n Node) bool {
+		if h, ok := n.(Headline); ok {
+			target := WorkspaceTarget{key, h.ID(), withoutSpans(h.Title)}
+			if id, ok := h.Properties.Get("ID"); ok {
+				w.IDs[id] = target
+			}
+			if customID, ok := h.Properties.Get("CUSTOM_ID"); ok {
+				w.CustomIDs[key][customID] = target
+			}
+		}
+		return true
+	}, d.Nodes...)
+}
+
+// hasWorkspaceLinks returns true if the document d of the file key contains links that Resolve resolves.
+func (w *Workspace) hasWorkspaceLinks(key string, d *Document) bool {
+	found := false
+	Inspect(func(n Node) bool {
+		if l, ok := n.(RegularLink); ok && !found {
+			_, found = w.Resolve(key, l.URL)
+		}
+		return !found
+	}, d.Nodes...)
+	return found
+}
+
+// key returns the slash separated path of the file at p relative to the root of the workspace.
+func (w *Workspace) key(p string) (string, error) {
+	root, err := filepath.Abs(w.Root)
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(root, abs)
+	return filepath.ToSlash(rel), err
+}
+
+func findHeadline(p string, sections []*Section, matches func(*Headline) bool) (WorkspaceTarget, bool) {
+	for _, s := range sections {
+		if matches(s.Headline) {
+			return WorkspaceTarget{p, s.Headline.ID(), withoutSpans(s.Headline.Title)}, true
+		} else if target, ok := findHeadline(p, s.Children, matches); ok {
+			return target, true
+		}
+	}
+	return WorkspaceTarget{}, false
+}
+
+// relativePath returns the slash separated path of to relative to the directory of from.
+func relativePath(from, to string) string {
+	rel, err := filepath.Rel(filepath.Dir(filepath.FromSlash(from)), filepath.FromSlash(to))
+	if err != nil {
+		return to
+	}
+	return filepath.ToSlash(rel)
+}
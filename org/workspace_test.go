@@ -0,0 +1,89 @@
+package org
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWorkspace(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"index.org": strings.Join([]string{
+			"#+TITLE: Index",
+			"* Links",
+			"[[id:42]] [[file:other.org::#custom]] [[file:sub/nested.org::*Nested headline][nested]] [[file:sub/nested.org]] [[file:missing.org]]",
+		}, "\n"),
+		"other.org": strings.Join([]string{
+			"#+TITLE: Other",
+			"* Custom",
+			":PROPERTIES:",
+			":CUSTOM_ID: custom",
+			":END:",
+			"[[file:index.org::*Links]] [[id:42]]",
+		}, "\n"),
+		"sub/nested.org": strings.Join([]string{
+			"#+TITLE: Nested",
+			"* First",
+			"* Nested headline",
+			":PROPERTIES:",
+			":ID: 42",
+			":END:",
+			"[[file:../other.org::#custom]] [[file:../index.org]]",
+		}, "\n"),
+		".hidden/ignored.org": "* Ignored [[file:../index.org]]",
+	}
+	w := newTestWorkspace(t, root, files)
+	if len(w.Documents) != 3 {
+		t.Errorf("got %d documents, expected 3", len(w.Documents))
+	}
+	expected := map[string][]string{
+		"index.org": {
+			`<a href="sub/nested.html#headline-2">Nested headline</a>`,
+			`<a href="other.html#custom">Custom</a>`,
+			`<a href="sub/nested.html#headline-2">nested</a>`,
+			`<a href="sub/nested.html">Nested</a>`,
+			`<a href="missing.html">missing.html</a>`,
+		},
+		"other.org": {
+			`<a href="index.html#headline-1">Links</a>`,
+			`<a href="sub/nested.html#headline-2">Nested headline</a>`,
+		},
+		"sub/nested.org": {
+			`<a href="../other.html#custom">Custom</a>`,
+			`<a href="../index.html">Index</a>`,
+		},
+	}
+	for name, links := range expected {
+		d, ok := w.Document(filepath.Join(root, filepath.FromSlash(name)))
+		if !ok {
+			t.Fatalf("missing document %s", name)
+		}
+		out, err := d.Write(NewHTMLWriter())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, link := range links {
+			if !strings.Contains(out, link) {
+				t.Errorf("%s: missing %s in\n%s", name, link, out)
+			}
+		}
+	}
+}
+
+func newTestWorkspace(t *testing.T, root string, files map[string]string) *Workspace {
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), os.ModePerm); err != nil {
+			t.Fatal(err)
+		} else if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w, err := NewWorkspace(root, New().Silent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
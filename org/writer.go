@@ -37,15 +37,22 @@ type Writer interface {
 	WriteExplicitLineBreak(ExplicitLineBreak)
 	WriteLineBreak(LineBreak)
 	WriteRegularLink(RegularLink)
+	WriteTarget(Target)
+	WriteRadioTarget(RadioTarget)
 	WriteMacro(Macro)
 	WriteTimestamp(Timestamp)
 	WriteFootnoteLink(FootnoteLink)
 	WriteFootnoteDefinition(FootnoteDefinition)
+	WriteClock(Clock)
+	WriteLogEntry(LogEntry)
 }
 
 func WriteNodes(w Writer, nodes ...Node) {
 	w = w.WriterWithExtensions()
 	for _, n := range nodes {
+		if sw, ok := w.(sourceWriter); ok && sw.writeSource(n) {
+			continue
+		}
 		switch n := n.(type) {
 		case Keyword:
 			w.WriteKeyword(n)
@@ -99,6 +106,10 @@ func WriteNodes(w Writer, nodes ...Node) {
 			w.WriteLineBreak(n)
 		case RegularLink:
 			w.WriteRegularLink(n)
+		case Target:
+			w.WriteTarget(n)
+		case RadioTarget:
+			w.WriteRadioTarget(n)
 		case Macro:
 			w.WriteMacro(n)
 		case Timestamp:
@@ -107,6 +118,10 @@ func WriteNodes(w Writer, nodes ...Node) {
 			w.WriteFootnoteLink(n)
 		case FootnoteDefinition:
 			w.WriteFootnoteDefinition(n)
+		case Clock:
+			w.WriteClock(n)
+		case LogEntry:
+			w.WriteLogEntry(n)
 		default:
 			if n != nil {
 				panic(fmt.Sprintf("bad node %T %#v", n, n))